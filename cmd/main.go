@@ -1,29 +1,141 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/wangmin362/ppu-device-plugin/pkg/config"
 	"github.com/wangmin362/ppu-device-plugin/pkg/deviceplugin"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
+// 构建信息，通过-ldflags "-X main.Version=..."注入
 var (
-	resourceName = flag.String("resource-name", "alibabacloud.com/ppu", "Resource name for the device plugin")
-	deviceCount  = flag.Int("device-count", 16, "Number of PPU devices to simulate")
-	logLevel     = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	socketPath   = flag.String("socket-path", "/var/lib/kubelet/device-plugins/", "Path for device plugin socket")
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
 )
 
+var (
+	showVersion         = flag.Bool("version", false, "Print version information and exit")
+	configPath          = flag.String("config", "", "Path to a YAML config file; explicitly set flags override its values")
+	deviceCountFile     = flag.String("device-count-file", "", "File holding the device count re-read on SIGHUP (defaults to re-reading --config)")
+	strictConfig        = flag.Bool("strict-config", false, "Fail instead of warning when --device-count disagrees with the config file")
+	resourceName        = flag.String("resource-name", config.DefaultResourceName, "Resource name for the device plugin")
+	deviceCount         = flag.Int("device-count", config.DefaultDeviceCount, "Number of PPU devices to simulate")
+	logLevel            = flag.String("log-level", config.DefaultLogLevel, "Log level (debug, info, warn, error)")
+	socketPath          = flag.String("socket-path", config.DefaultSocketPath, "Path for device plugin socket")
+	socketMode          = flag.String("socket-mode", "0600", "Octal permissions applied to the plugin socket file")
+	socketDirMode       = flag.String("socket-dir-mode", "0755", "Octal permissions used when creating the socket directory")
+	enableAdminAPI      = flag.Bool("enable-admin-api", false, "Serve the unauthenticated admin endpoints (devices, reserve, reset, maintenance, health injection) next to /metrics and /readyz")
+	pprofAddr           = flag.String("pprof-addr", "", "Listen address for the net/http/pprof profiling server (empty disables it)")
+	metricsAddr         = flag.String("metrics-addr", ":9400", "Listen address for the metrics and admin HTTP server (empty disables it)")
+	stateFile           = flag.String("state-file", "", "Path to persist allocation state across restarts (empty disables it)")
+	auditLog            = flag.String("audit-log", "", "Path of a JSON lines file recording every allocation (empty disables auditing)")
+	podResourcesSocket  = flag.String("pod-resources-socket", "", "Socket path for the PodResources-compatible allocation listing (empty disables it)")
+	perDeviceMetrics    = flag.Bool("per-device-metrics", false, "Export per-device allocation counters (one series per device)")
+	emptyOnAllUnhealthy = flag.Bool("empty-on-all-unhealthy", false, "Advertise an empty device list instead of all-unhealthy devices when no device is healthy")
+	preferredAllocation = flag.Bool("preferred-allocation", true, "Advertise GetPreferredAllocation to the kubelet (false makes it return Unimplemented)")
+	preStartRequired    = flag.Bool("prestart-required", false, "Ask the kubelet to call PreStartContainer and validate the requested device IDs there")
+	strictDeviceIDs     = flag.Bool("strict-device-ids", false, "Reject Allocate requests for device IDs that do not match the configured --device-id-format")
+	allocationTTL       = flag.Duration("allocation-ttl", 0, "Mark allocated devices busy for this long, then release them automatically (0 keeps allocations until released)")
+	cacheAllocations    = flag.Bool("cache-allocations", false, "Return the cached response when the kubelet retries Allocate with the same device IDs")
+	maxPerContainer     = flag.Int("max-devices-per-container", 0, "Maximum number of devices a single container may be allocated (0 means unlimited)")
+	envCountKey         = flag.String("env-count-key", deviceplugin.DefaultEnvCountKey, "Env var name carrying the allocated device count")
+	envDevicesKey       = flag.String("env-devices-key", deviceplugin.DefaultEnvDevicesKey, "Env var name carrying the allocated device IDs")
+	annotationPrefix    = flag.String("annotation-prefix", deviceplugin.DefaultAnnotationPrefix, "Namespace of the annotations added to Allocate responses")
+	extraEnvs           = flag.String("extra-envs", "", "Static env vars added to every allocation, e.g. KEY=val,KEY2=val2")
+	deviceModel         = flag.String("device-model", "", "Device model passed to containers as PPU_<n>_MODEL (empty omits it)")
+	deviceMemory        = flag.Int("device-memory", 0, "Device memory in MB passed to containers as PPU_<n>_MEMORY_MB (0 omits it)")
+	devicePathTemplate  = flag.String("device-path-template", "", "Device node path template with the device index substituted, e.g. /dev/ppu%d (empty maps devices to /dev/null)")
+	enableCDI           = flag.Bool("enable-cdi", false, "Return CDI device names (e.g. alibabacloud.com/ppu=ppu-0) instead of device specs in Allocate")
+	shmSize             = flag.String("shm-size", "", "Shared memory size, e.g. 64Mi; mounts /dev/shm into every allocation (empty disables)")
+	hugepages           = flag.String("hugepages", "", "Hugepage sizes, e.g. 2Mi,1Gi; mounts /dev/hugepages-<size> into every allocation")
+	mounts              = flag.String("mounts", "", "Mounts added to every allocation, e.g. /host/lib:/usr/lib/ppu:ro,/host/bin:/usr/bin/ppu")
+	allocateLatency     = flag.Duration("allocate-latency", 0, "Base delay injected into every Allocate call")
+	allocateJitter      = flag.Duration("allocate-latency-jitter", 0, "Random extra delay in [0, jitter) added to every Allocate call")
+	allocDelayPerDevice = flag.Duration("allocation-delay-per-device", 0, "Additional Allocate delay per requested device")
+	allocationStrategy  = flag.String("allocation-strategy", deviceplugin.AllocationStrategyPacked, "Preferred allocation strategy (packed, spread, numa-packed, interconnect, temperature-aware, or one registered with RegisterStrategy)")
+	interconnectGroups  = flag.String("interconnect-groups", "", "Interconnect groups used by the interconnect strategy, e.g. ppu-0,ppu-1;ppu-2,ppu-3")
+	powerDomains        = flag.String("power-domains", "", "Power domain groups, e.g. ppu-0,ppu-1;ppu-2,ppu-3")
+	powerStrategy       = flag.String("power-strategy", "", "Power-aware preferred allocation strategy (concentrate, spread)")
+	firmwareVersions    = flag.String("firmware-versions", "", "Firmware versions per device subset, e.g. v1:ppu-0,ppu-1;v2:ppu-2,ppu-3")
+	firmwareHomogeneous = flag.Bool("firmware-homogeneous", false, "Prefer allocating devices with the same firmware version to a container")
+	numaNodes           = flag.Int("numa-nodes", 2, "Number of NUMA nodes devices are distributed across (0 disables topology hints)")
+	multiNUMADevices    = flag.String("multi-numa-devices", "", "Comma-separated device IDs spanning two NUMA nodes (their node and the next one)")
+	preferredNUMANode   = flag.Int64("preferred-numa-node", -1, "NUMA node preferred allocations are biased toward; overridden by request metadata (negative disables)")
+	deviceIDFormat      = flag.String("device-id-format", deviceplugin.DeviceIDFormatIndex, "Device ID naming scheme: index (ppu-0), uuid, or a template with one %d such as gpu-%d")
+	deviceIDSeed        = flag.Int64("device-id-seed", 0, "Seed for --device-id-format=uuid; the same seed yields the same IDs across restarts")
+	partitions          = flag.Int("partitions-per-device", 0, "Number of partitions each device is split into, advertised as ppu-<n>-<k> (0 disables partitioning)")
+	healthInterval      = flag.Duration("health-check-interval", 30*time.Second, "Interval between device health checks")
+	healthBufferSize    = flag.Int("health-buffer-size", 64, "Capacity of the health update channel; updates are dropped and counted when it is full")
+	healthSendTimeout   = flag.Duration("health-send-timeout", 0, "How long a health update waits for room in a full channel before it is dropped (0 drops immediately)")
+	healthJitter        = flag.Float64("health-jitter", 0, "Fraction (0-1) by which each health check interval is randomly varied, e.g. 0.1 for ±10%")
+	healthCommand       = flag.String("health-command", "", "Command run per device on every health check with the device ID as $1; exit code 0 means healthy")
+	healthCmdTimeout    = flag.Duration("health-command-timeout", 5*time.Second, "Timeout for a single --health-command invocation; timeouts count as unhealthy")
+	shuffleDevices      = flag.Bool("shuffle-devices", false, "Shuffle the advertised device order on every ListAndWatch send")
+	shuffleSeed         = flag.Int64("shuffle-seed", 0, "Seed for --shuffle-devices (0 uses the current time)")
+	deadDevices         = flag.String("dead-devices", "", "Comma-separated device IDs that are permanently dead: never recover and are rejected by Allocate")
+	initialHealth       = flag.String("initial-health", "", "Initial health distribution in device order or per ID, e.g. healthy:12,unhealthy:4 or ppu-0:unhealthy")
+	initialTransient    = flag.Bool("initial-health-transient", false, "Let devices made unhealthy by --initial-health recover on the next health check instead of staying unhealthy")
+	unhealthyDevices    = flag.String("unhealthy-devices", "", "Comma-separated device IDs to mark unhealthy, e.g. ppu-3,ppu-7")
+	chaosSeed           = flag.Int64("chaos-seed", 0, "Seed for random health failures (0 falls back to --rng-seed)")
+	deterministic       = flag.Bool("deterministic", false, "Drive all randomness from a single generator seeded with --rng-seed so runs with the same seed are identical")
+	rngSeed             = flag.Int64("rng-seed", 0, "Seed for the plugin's random number generator used by failure injection and jitter (0 uses the current time)")
+	allocFailureRate    = flag.Float64("allocate-failure-rate", 0, "Probability (0-1) that an Allocate call fails with a simulated Internal error")
+	unhealthyRatio      = flag.Float64("unhealthy-ratio", 0, "Fraction of devices (0-1) randomly marked unhealthy on startup")
+	watchDebounce       = flag.Duration("watch-debounce", 500*time.Millisecond, "Window for coalescing device health changes into a single ListAndWatch update")
+	listWatchInterval   = flag.Duration("list-watch-min-interval", time.Second, "Minimum interval between ListAndWatch reconnects from the same client")
+	noRegister          = flag.Bool("no-register", false, "Serve the device plugin socket without registering with the kubelet (dry run)")
+	maxDeviceCount      = flag.Int("max-device-count", deviceplugin.DefaultMaxDeviceCount, "Upper bound for the device count of each resource")
+	historySize         = flag.Int("history-size", 100, "Number of recent allocations kept for /history")
+	maintenance         = flag.Bool("maintenance", false, "Start in maintenance mode, advertising no devices while staying registered")
+	listWatchDelay      = flag.Duration("list-watch-initial-delay", 0, "Delay before ListAndWatch sends the first device list, simulating a slow-starting plugin")
+	watchSendRetries    = flag.Int("watch-send-retries", 3, "Number of ListAndWatch send retries before returning the error to kubelet")
+	watchSendBackoff    = flag.Duration("watch-send-backoff", 100*time.Millisecond, "Delay before the first ListAndWatch send retry, doubled after each failure")
+	registerRetries     = flag.Int("register-retries", 5, "Number of kubelet registration retries before giving up")
+	registerBackoff     = flag.Duration("register-backoff", time.Second, "Delay before the first registration retry, doubled after each failure")
+	drainGrace          = flag.Duration("drain-grace", 0, "On SIGTERM, advertise no devices and wait this long before shutting down (0 stops immediately)")
+	shutdownTimeout     = flag.Duration("shutdown-timeout", 10*time.Second, "Maximum time to wait for in-flight requests on shutdown before forcing stop")
+	warmup              = flag.Duration("warmup", 0, "Duration after start during which allocations are rejected as unavailable")
+)
+
+// resources 通过--resource指定的资源列表
+var resources resourceFlags
+
+func init() {
+	flag.Var(&resources, "resource", "Resource to advertise as name:count; repeat to run several resources in one process (overrides --resource-name/--device-count)")
+}
+
 func main() {
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Printf("ppu-device-plugin %s (commit %s, built %s, device plugin API %s)\n",
+			Version, Commit, BuildDate, v1beta1.Version)
+		os.Exit(0)
+	}
+
+	// 加载配置文件并与命令行参数合并
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// 配置日志级别
-	level, err := log.ParseLevel(*logLevel)
+	level, err := log.ParseLevel(cfg.LogLevel)
 	if err != nil {
-		log.Fatalf("Invalid log level: %s", *logLevel)
+		log.Fatalf("Invalid log level: %s", cfg.LogLevel)
 	}
 	log.SetLevel(level)
 
@@ -33,30 +145,432 @@ func main() {
 		ForceColors:   true,
 	})
 
-	log.Infof("Starting PPU Device Plugin")
-	log.Infof("Resource Name: %s", *resourceName)
-	log.Infof("Device Count: %d", *deviceCount)
-	log.Infof("Log Level: %s", *logLevel)
-	log.Infof("Socket Path: %s", *socketPath)
+	log.Infof("Starting PPU Device Plugin %s (commit %s, built %s)", Version, Commit, BuildDate)
+	log.Infof("Resource Name: %s", cfg.ResourceName)
+	log.Infof("Device Count: %d", cfg.DeviceCount)
+	log.Infof("Log Level: %s", cfg.LogLevel)
+	log.Infof("Socket Path: %s", cfg.SocketPath)
+	log.Infof("Metrics Address: %s", *metricsAddr)
+	log.Infof("Health Check Interval: %s", *healthInterval)
 
-	// 创建设备插件实例
-	plugin := deviceplugin.NewPPUDevicePlugin(*resourceName, *deviceCount, *socketPath)
+	if *shuffleSeed == 0 {
+		*shuffleSeed = time.Now().UnixNano()
+	}
+
+	if *unhealthyRatio < 0 || *unhealthyRatio > 1 {
+		log.Fatalf("Invalid unhealthy ratio %v: must be between 0 and 1", *unhealthyRatio)
+	}
+	if *allocFailureRate < 0 || *allocFailureRate > 1 {
+		log.Fatalf("Invalid allocate failure rate %v: must be between 0 and 1", *allocFailureRate)
+	}
+	if *healthBufferSize <= 0 {
+		log.Fatalf("Invalid health buffer size %d: must be greater than 0", *healthBufferSize)
+	}
+	if *healthJitter < 0 || *healthJitter >= 1 {
+		log.Fatalf("Invalid health jitter %v: must be in [0, 1)", *healthJitter)
+	}
+	if *historySize <= 0 {
+		log.Fatalf("Invalid history size %d: must be greater than 0", *historySize)
+	}
+	if *watchSendRetries < 0 {
+		log.Fatalf("Invalid watch send retries %d: must not be negative", *watchSendRetries)
+	}
+	if *deviceMemory < 0 {
+		log.Fatalf("Invalid device memory %d: must not be negative", *deviceMemory)
+	}
+	if *maxPerContainer < 0 {
+		log.Fatalf("Invalid max devices per container %d: must not be negative", *maxPerContainer)
+	}
+	if *partitions < 0 {
+		log.Fatalf("Invalid partitions per device %d: must not be negative", *partitions)
+	}
+
+	// 解析初始健康状态分布
+	initial, err := deviceplugin.ParseInitialHealth(*initialHealth)
+	if err != nil {
+		log.Fatalf("Invalid initial health %q: %v", *initialHealth, err)
+	}
+
+	// 解析静态环境变量
+	envs, err := deviceplugin.ParseEnvs(*extraEnvs)
+	if err != nil {
+		log.Fatalf("Invalid extra envs %q: %v", *extraEnvs, err)
+	}
+
+	// 解析socket权限
+	pluginSocketMode, err := deviceplugin.ParseFileMode(*socketMode)
+	if err != nil {
+		log.Fatalf("Invalid --socket-mode: %v", err)
+	}
+	pluginSocketDirMode, err := deviceplugin.ParseFileMode(*socketDirMode)
+	if err != nil {
+		log.Fatalf("Invalid --socket-dir-mode: %v", err)
+	}
+
+	// 解析挂载配置
+	if err := deviceplugin.ValidateShmSize(*shmSize); err != nil {
+		log.Fatalf("Invalid --shm-size: %v", err)
+	}
+	hugepageSizes, err := deviceplugin.ParseHugepages(*hugepages)
+	if err != nil {
+		log.Fatalf("Invalid --hugepages: %v", err)
+	}
+	allocationMounts, err := deviceplugin.ParseMounts(*mounts)
+	if err != nil {
+		log.Fatalf("Invalid mounts %q: %v", *mounts, err)
+	}
+	if *devicePathTemplate != "" && !strings.Contains(*devicePathTemplate, "%d") {
+		log.Fatalf("Invalid device path template %q: must contain %%d", *devicePathTemplate)
+	}
+
+	// 解析固件版本配置
+	versions, err := deviceplugin.ParseFirmwareVersions(*firmwareVersions)
+	if err != nil {
+		log.Fatalf("Invalid firmware versions %q: %v", *firmwareVersions, err)
+	}
+
+	// 解析互联组配置
+	interconnect, err := deviceplugin.ParseDeviceGroups(*interconnectGroups)
+	if err != nil {
+		log.Fatalf("Invalid interconnect groups %q: %v", *interconnectGroups, err)
+	}
+
+	// 解析供电域配置
+	domains, err := deviceplugin.ParseDeviceGroups(*powerDomains)
+	if err != nil {
+		log.Fatalf("Invalid power domains %q: %v", *powerDomains, err)
+	}
+	if !validAllocationStrategy(*allocationStrategy) {
+		log.Fatalf("Invalid allocation strategy: %s", *allocationStrategy)
+	}
+	if *powerStrategy != "" && *powerStrategy != deviceplugin.PowerStrategyConcentrate && *powerStrategy != deviceplugin.PowerStrategySpread {
+		log.Fatalf("Invalid power strategy: %s", *powerStrategy)
+	}
+
+	opts := deviceplugin.Options{
+		BuildInfo:                  deviceplugin.BuildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate},
+		SocketMode:                 pluginSocketMode,
+		SocketDirMode:              pluginSocketDirMode,
+		MetricsAddr:                *metricsAddr,
+		EnableAdminAPI:             *enableAdminAPI,
+		PprofAddr:                  *pprofAddr,
+		PerDeviceMetrics:           *perDeviceMetrics,
+		EmptyOnAllUnhealthy:        *emptyOnAllUnhealthy,
+		PreStartRequired:           *preStartRequired,
+		DisablePreferredAllocation: !*preferredAllocation,
+		Warmup:                     *warmup,
+		StrictDeviceIDs:            *strictDeviceIDs,
+		MaxDevicesPerContainer:     *maxPerContainer,
+		CacheAllocations:           *cacheAllocations,
+		AllocationTTL:              *allocationTTL,
+		EnvCountKey:                *envCountKey,
+		EnvDevicesKey:              *envDevicesKey,
+		AnnotationPrefix:           *annotationPrefix,
+		ExtraEnvs:                  envs,
+		DeviceModel:                *deviceModel,
+		DeviceMemoryMB:             *deviceMemory,
+		DevicePathTemplate:         *devicePathTemplate,
+		Mounts:                     allocationMounts,
+		ShmSize:                    *shmSize,
+		Hugepages:                  hugepageSizes,
+		EnableCDI:                  *enableCDI,
+		AllocateLatency:            *allocateLatency,
+		AllocateLatencyJitter:      *allocateJitter,
+		AllocationDelayPerDevice:   *allocDelayPerDevice,
+		ShuffleDevices:             *shuffleDevices,
+		ShuffleSeed:                *shuffleSeed,
+		FirmwareVersions:           versions,
+		FirmwareHomogeneous:        *firmwareHomogeneous,
+		UnhealthyDevices:           splitList(*unhealthyDevices),
+		DeadDevices:                splitList(*deadDevices),
+		UnhealthyRatio:             *unhealthyRatio,
+		InitialHealth:              initial,
+		InitialHealthTransient:     *initialTransient,
+		ChaosSeed:                  *chaosSeed,
+		RNGSeed:                    *rngSeed,
+		Deterministic:              *deterministic,
+		AllocateFailureRate:        *allocFailureRate,
+		HealthCheckInterval:        *healthInterval,
+		HealthJitter:               *healthJitter,
+		HealthBufferSize:           *healthBufferSize,
+		HealthSendTimeout:          *healthSendTimeout,
+		HealthCommand:              *healthCommand,
+		HealthCommandTimeout:       *healthCmdTimeout,
+		NUMANodes:                  *numaNodes,
+		MultiNUMADevices:           splitList(*multiNUMADevices),
+		PreferredNUMANode:          preferredNUMANodeOption(*preferredNUMANode),
+		PartitionsPerDevice:        *partitions,
+		DeviceIDFormat:             *deviceIDFormat,
+		DeviceIDSeed:               *deviceIDSeed,
+		ShutdownTimeout:            *shutdownTimeout,
+		DrainGrace:                 *drainGrace,
+		NoRegister:                 *noRegister,
+		RegisterRetries:            *registerRetries,
+		RegisterBackoff:            *registerBackoff,
+		PodResourcesSocket:         *podResourcesSocket,
+		AuditLog:                   *auditLog,
+		StateFile:                  *stateFile,
+		ListAndWatchMinInterval:    *listWatchInterval,
+		WatchDebounce:              *watchDebounce,
+		WatchSendRetries:           *watchSendRetries,
+		ListAndWatchInitialDelay:   *listWatchDelay,
+		Maintenance:                *maintenance,
+		HistorySize:                *historySize,
+		MaxDeviceCount:             *maxDeviceCount,
+		WatchSendBackoff:           *watchSendBackoff,
+		DeviceOverrides:            deviceOverrides(cfg.Devices),
+		AllocationStrategy:         *allocationStrategy,
+		InterconnectGroups:         interconnect,
+		PowerDomains:               domains,
+		PowerStrategy:              *powerStrategy,
+	}
+
+	// 创建设备插件实例，每个资源一个实例
+	plugins := newPlugins(cfg, opts)
+	for _, plugin := range plugins {
+		if err := plugin.Validate(); err != nil {
+			log.Fatalf("Invalid device plugin configuration: %v", err)
+		}
+	}
+	manager := deviceplugin.NewManager(plugins...)
+
+	// 监听系统信号，收到信号时取消上下文
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// 启动设备插件
-	if err := plugin.Start(); err != nil {
+	if err := manager.StartContext(ctx); err != nil {
 		log.Fatalf("Failed to start device plugin: %v", err)
 	}
 
 	// 启动健康检查
-	plugin.StartHealthCheck()
+	manager.StartHealthCheckContext(ctx)
 
-	// 监听系统信号
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// 收到SIGHUP时重新读取设备数量，模拟设备热插拔
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go reloadOnSignal(ctx, hup, manager)
 
 	log.Info("PPU Device Plugin is running...")
-	<-sigChan
+	<-ctx.Done()
 
 	log.Info("Shutting down PPU Device Plugin...")
-	plugin.Stop()
+	if *drainGrace > 0 {
+		// 排空期间再次收到信号时立即停止
+		drainCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		manager.Drain(drainCtx)
+		cancel()
+	}
+	manager.Stop()
+}
+
+// reloadOnSignal 每次收到SIGHUP时重新读取设备数量并应用到插件，直到ctx取消
+func reloadOnSignal(ctx context.Context, hup <-chan os.Signal, manager *deviceplugin.Manager) {
+	for {
+		select {
+		case <-hup:
+			if len(resources) > 0 {
+				log.Warn("Ignoring SIGHUP: device count reload is not supported with --resource")
+				continue
+			}
+
+			count, err := reloadDeviceCount()
+			if err != nil {
+				log.Errorf("Failed to reload device count: %v", err)
+				continue
+			}
+			log.Infof("Reloading device count: %d", count)
+			if err := manager.Plugins()[0].SetDeviceCount(count); err != nil {
+				log.Errorf("Failed to apply device count %d: %v", count, err)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadDeviceCount 从--device-count-file或--config重新读取设备数量
+func reloadDeviceCount() (int, error) {
+	if *deviceCountFile != "" {
+		data, err := os.ReadFile(*deviceCountFile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read device count file: %v", err)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid device count in %s: %v", *deviceCountFile, err)
+		}
+		return count, nil
+	}
+
+	if *configPath != "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return 0, err
+		}
+		return cfg.DeviceCount, nil
+	}
+
+	return 0, fmt.Errorf("neither --device-count-file nor --config is set")
+}
+
+// resourceFlags 可重复的--resource参数
+type resourceFlags []deviceplugin.ResourceSpec
+
+func (r *resourceFlags) String() string {
+	specs := make([]string, 0, len(*r))
+	for _, spec := range *r {
+		specs = append(specs, fmt.Sprintf("%s:%d", spec.Name, spec.Count))
+	}
+	return strings.Join(specs, ",")
+}
+
+func (r *resourceFlags) Set(value string) error {
+	spec, err := deviceplugin.ParseResourceSpec(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, spec)
+	return nil
+}
+
+// newPlugins 为每个资源创建插件实例，socket名称由资源名称生成；
+// 指定多个资源时第一个资源使用配置的地址和路径，之后的资源由其派生：监听端口依次加一，文件路径追加资源名称
+func newPlugins(cfg *config.Config, opts deviceplugin.Options) []*deviceplugin.PPUDevicePlugin {
+	if len(resources) == 0 {
+		return []*deviceplugin.PPUDevicePlugin{
+			deviceplugin.NewPPUDevicePluginWithOptions(cfg.ResourceName, cfg.DeviceCount, cfg.SocketPath, opts),
+		}
+	}
+
+	plugins := make([]*deviceplugin.PPUDevicePlugin, 0, len(resources))
+	for i, spec := range resources {
+		resourceOpts := opts
+		if i > 0 {
+			resourceOpts.MetricsAddr = resourceAddr(spec.Name, "metrics address", opts.MetricsAddr, i)
+			resourceOpts.PprofAddr = resourceAddr(spec.Name, "pprof address", opts.PprofAddr, i)
+			resourceOpts.PodResourcesSocket = resourcePath(spec.Name, "pod resources socket", opts.PodResourcesSocket)
+			resourceOpts.StateFile = resourcePath(spec.Name, "state file", opts.StateFile)
+		}
+
+		plugin := deviceplugin.NewPPUDevicePluginWithOptions(spec.Name, spec.Count, cfg.SocketPath, resourceOpts)
+		log.Infof("Resource %s: %d devices on socket %s", spec.Name, spec.Count, plugin.Socket())
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// resourceAddr 为第offset个资源派生监听地址：端口加offset，端口为0时保持随机端口；地址为空时不启用，无法解析时禁用并告警
+func resourceAddr(resourceName, what, addr string, offset int) string {
+	if addr == "" {
+		return ""
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	port, convErr := strconv.Atoi(portStr)
+	if err != nil || convErr != nil {
+		log.Warnf("Resource %s: cannot derive %s from %q, disabling it for this resource", resourceName, what, addr)
+		return ""
+	}
+	if port != 0 {
+		port += offset
+	}
+
+	derived := net.JoinHostPort(host, strconv.Itoa(port))
+	log.Infof("Resource %s: using %s %s", resourceName, what, derived)
+	return derived
+}
+
+// resourcePath 为资源派生文件路径：在扩展名前追加资源名称，如state.json对应state-alibabacloud.com_ppu-shared.json；路径为空时不启用
+func resourcePath(resourceName, what, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	ext := filepath.Ext(path)
+	suffix := strings.TrimSuffix(deviceplugin.SocketNameForResource(resourceName), ".sock")
+	derived := strings.TrimSuffix(path, ext) + "-" + suffix + ext
+	log.Infof("Resource %s: using %s %s", resourceName, what, derived)
+	return derived
+}
+
+// loadConfig 加载--config指定的配置文件，显式设置的命令行参数优先于文件中的值（设备数量除外）
+func loadConfig() (*config.Config, error) {
+	cfg := config.Default()
+	if *configPath != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+
+	var visitErr error
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "resource-name":
+			cfg.ResourceName = *resourceName
+		case "device-count":
+			// 设备数量与配置文件冲突时以配置文件为准
+			visitErr = cfg.ResolveDeviceCount(*deviceCount, *strictConfig)
+		case "socket-path":
+			cfg.SocketPath = *socketPath
+		case "log-level":
+			cfg.LogLevel = *logLevel
+		}
+	})
+	if visitErr != nil {
+		return nil, visitErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// deviceOverrides 将配置文件中的设备覆盖转换为插件选项
+func deviceOverrides(devices []config.DeviceOverride) map[string]deviceplugin.DeviceOverride {
+	overrides := make(map[string]deviceplugin.DeviceOverride, len(devices))
+	for _, device := range devices {
+		overrides[device.ID] = deviceplugin.DeviceOverride{
+			Health:             device.Health,
+			NUMANode:           device.NUMANode,
+			FailureProbability: device.FailureProbability,
+			Model:              device.Model,
+			MemoryMB:           device.MemoryMB,
+		}
+	}
+	return overrides
+}
+
+// preferredNUMANodeOption 将--preferred-numa-node转换为插件配置，负数表示不偏向任何节点
+func preferredNUMANodeOption(node int64) *int64 {
+	if node < 0 {
+		return nil
+	}
+	return &node
+}
+
+// validAllocationStrategy 判断分配策略是否受支持
+func validAllocationStrategy(strategy string) bool {
+	for _, s := range deviceplugin.RegisteredStrategies() {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList 解析逗号分隔的列表，忽略空白项
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,94 @@
+// Package client 提供通过unix socket调用设备插件gRPC接口的客户端，便于测试和脚本化驱动模拟插件
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// Client 设备插件客户端，以kubelet的身份调用插件socket上的DevicePlugin服务
+type Client struct {
+	conn   *grpc.ClientConn
+	plugin v1beta1.DevicePluginClient
+}
+
+// NewClient 创建连接到插件socket的客户端，连接在首次调用时建立
+func NewClient(socketPath string) (*Client, error) {
+	conn, err := grpc.NewClient("passthrough:///"+socketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %v", socketPath, err)
+	}
+
+	return &Client{conn: conn, plugin: v1beta1.NewDevicePluginClient(conn)}, nil
+}
+
+// Close 关闭客户端连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// GetDevicePluginOptions 获取插件声明的选项
+func (c *Client) GetDevicePluginOptions(ctx context.Context) (*v1beta1.DevicePluginOptions, error) {
+	return c.plugin.GetDevicePluginOptions(ctx, &v1beta1.Empty{})
+}
+
+// Watch ListAndWatch的设备列表流
+type Watch struct {
+	stream v1beta1.DevicePlugin_ListAndWatchClient
+}
+
+// Next 阻塞直到插件上报下一次设备列表，流结束或ctx取消时返回错误
+func (w *Watch) Next() ([]*v1beta1.Device, error) {
+	response, err := w.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return response.Devices, nil
+}
+
+// ListAndWatch 打开设备列表流，ctx取消时流结束
+func (c *Client) ListAndWatch(ctx context.Context) (*Watch, error) {
+	stream, err := c.plugin.ListAndWatch(ctx, &v1beta1.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return &Watch{stream: stream}, nil
+}
+
+// Allocate 为每个容器分配设备，每个参数为一个容器请求的设备ID列表
+func (c *Client) Allocate(ctx context.Context, containers ...[]string) (*v1beta1.AllocateResponse, error) {
+	request := &v1beta1.AllocateRequest{}
+	for _, deviceIDs := range containers {
+		request.ContainerRequests = append(request.ContainerRequests, &v1beta1.ContainerAllocateRequest{DevicesIDs: deviceIDs})
+	}
+	return c.plugin.Allocate(ctx, request)
+}
+
+// GetPreferredAllocation 为单个容器获取首选设备，available为可选设备，mustInclude为必须包含的设备
+func (c *Client) GetPreferredAllocation(ctx context.Context, available, mustInclude []string, size int) ([]string, error) {
+	response, err := c.plugin.GetPreferredAllocation(ctx, &v1beta1.PreferredAllocationRequest{
+		ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs:   available,
+			MustIncludeDeviceIDs: mustInclude,
+			AllocationSize:       int32(size),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.ContainerResponses) != 1 {
+		return nil, fmt.Errorf("expected 1 container response, got %d", len(response.ContainerResponses))
+	}
+	return response.ContainerResponses[0].DeviceIDs, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wangmin362/ppu-device-plugin/pkg/deviceplugin"
+)
+
+// startPlugin 在临时目录以不注册模式启动插件，返回连接其socket的客户端
+func startPlugin(t *testing.T, deviceCount int) *Client {
+	t.Helper()
+
+	plugin := deviceplugin.NewPPUDevicePluginWithOptions("test.com/ppu", deviceCount, t.TempDir(), deviceplugin.Options{NoRegister: true})
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(plugin.Stop)
+
+	c, err := NewClient(plugin.Socket())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// TestClient 测试客户端通过插件socket调用各个RPC
+func TestClient(t *testing.T) {
+	c := startPlugin(t, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options, err := c.GetDevicePluginOptions(ctx)
+	if err != nil {
+		t.Fatalf("GetDevicePluginOptions failed: %v", err)
+	}
+	if !options.GetPreferredAllocationAvailable {
+		t.Error("Expected preferred allocation to be available")
+	}
+
+	watch, err := c.ListAndWatch(ctx)
+	if err != nil {
+		t.Fatalf("ListAndWatch failed: %v", err)
+	}
+	devices, err := watch.Next()
+	if err != nil {
+		t.Fatalf("Watch.Next failed: %v", err)
+	}
+	if len(devices) != 4 {
+		t.Errorf("Expected 4 devices, got %d", len(devices))
+	}
+
+	preferred, err := c.GetPreferredAllocation(ctx, []string{"ppu-3", "ppu-2", "ppu-1", "ppu-0"}, []string{"ppu-2"}, 2)
+	if err != nil {
+		t.Fatalf("GetPreferredAllocation failed: %v", err)
+	}
+	if expected := []string{"ppu-2", "ppu-0"}; !reflect.DeepEqual(preferred, expected) {
+		t.Errorf("Expected preferred devices %v, got %v", expected, preferred)
+	}
+
+	response, err := c.Allocate(ctx, []string{"ppu-0"}, []string{"ppu-1", "ppu-2"})
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	if len(response.ContainerResponses) != 2 {
+		t.Fatalf("Expected 2 container responses, got %d", len(response.ContainerResponses))
+	}
+	if got := response.ContainerResponses[1].Envs["PPU_ALLOCATED_DEVICES"]; got != "ppu-1,ppu-2" {
+		t.Errorf("Expected ppu-1,ppu-2 for the second container, got %q", got)
+	}
+}
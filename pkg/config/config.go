@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// DefaultResourceName 默认的资源名称
+	DefaultResourceName = "alibabacloud.com/ppu"
+	// DefaultDeviceCount 默认模拟的设备数量
+	DefaultDeviceCount = 16
+	// DefaultSocketPath 默认的设备插件socket目录
+	DefaultSocketPath = "/var/lib/kubelet/device-plugins/"
+	// DefaultLogLevel 默认的日志级别
+	DefaultLogLevel = "info"
+)
+
+// DeviceOverride 单个设备的配置覆盖
+type DeviceOverride struct {
+	// ID 设备ID，如ppu-3
+	ID string `yaml:"id"`
+	// Health 设备的初始健康状态（Healthy或Unhealthy），为空时保持默认
+	Health string `yaml:"health"`
+	// NUMANode 设备所在的NUMA节点，为空时保持默认分布
+	NUMANode *int64 `yaml:"numaNode"`
+	// FailureProbability 每次健康检查时设备变为不健康的概率（0-1）
+	FailureProbability float64 `yaml:"failureProbability"`
+	// Model 设备型号，为空时使用全局配置
+	Model string `yaml:"model"`
+	// MemoryMB 设备显存大小（MB），为零时使用全局配置
+	MemoryMB int `yaml:"memoryMB"`
+}
+
+// Config 设备插件的文件配置
+type Config struct {
+	ResourceName string           `yaml:"resourceName"`
+	DeviceCount  int              `yaml:"deviceCount"`
+	SocketPath   string           `yaml:"socketPath"`
+	LogLevel     string           `yaml:"logLevel"`
+	Devices      []DeviceOverride `yaml:"devices"`
+
+	// deviceCountSet 配置文件是否显式指定了deviceCount
+	deviceCountSet bool
+}
+
+// Default 返回使用默认值的配置
+func Default() *Config {
+	return &Config{
+		ResourceName: DefaultResourceName,
+		DeviceCount:  DefaultDeviceCount,
+		SocketPath:   DefaultSocketPath,
+		LogLevel:     DefaultLogLevel,
+	}
+}
+
+// Load 从YAML文件加载配置，文件中未指定的字段使用默认值
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	cfg := Default()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	// 再解析一次文档树，用于判断字段是否出现以及定位错误所在的行列
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	if len(root.Content) > 0 {
+		cfg.deviceCountSet = child(root.Content[0], "deviceCount") != nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			fieldErr.locate(&root)
+		}
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ResolveDeviceCount 合并命令行指定的设备数量。配置文件显式指定了不同的数量时以配置文件为准并记录警告，
+// strict为true时返回错误
+func (c *Config) ResolveDeviceCount(flagCount int, strict bool) error {
+	if !c.deviceCountSet {
+		c.DeviceCount = flagCount
+		return nil
+	}
+	if flagCount == c.DeviceCount {
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("--device-count=%d conflicts with deviceCount %d in config file", flagCount, c.DeviceCount)
+	}
+	log.Warnf("--device-count=%d conflicts with deviceCount %d in config file, using %d from config", flagCount, c.DeviceCount, c.DeviceCount)
+	return nil
+}
+
+// Validate 校验配置是否合法，字段错误以*FieldError返回
+func (c *Config) Validate() error {
+	if c.DeviceCount <= 0 {
+		return fieldError([]interface{}{"deviceCount"}, "deviceCount must be greater than 0, got %d", c.DeviceCount)
+	}
+	if !strings.Contains(c.ResourceName, "/") {
+		return fieldError([]interface{}{"resourceName"}, "resourceName %q must be of the form <vendor>/<resource>", c.ResourceName)
+	}
+
+	seen := make(map[string]bool, len(c.Devices))
+	for i, device := range c.Devices {
+		if device.ID == "" {
+			return fieldError([]interface{}{"devices", i}, "devices[%d]: id is required", i)
+		}
+		if seen[device.ID] {
+			return fieldError([]interface{}{"devices", i, "id"}, "devices[%d]: duplicate override for device %s", i, device.ID)
+		}
+		seen[device.ID] = true
+
+		if device.Health != "" && device.Health != v1beta1.Healthy && device.Health != v1beta1.Unhealthy {
+			return fieldError([]interface{}{"devices", i, "health"}, "devices[%d]: invalid health %q for device %s", i, device.Health, device.ID)
+		}
+		if device.FailureProbability < 0 || device.FailureProbability > 1 {
+			return fieldError([]interface{}{"devices", i, "failureProbability"},
+				"devices[%d]: failureProbability %v for device %s must be between 0 and 1", i, device.FailureProbability, device.ID)
+		}
+		if device.NUMANode != nil && *device.NUMANode < 0 {
+			return fieldError([]interface{}{"devices", i, "numaNode"}, "devices[%d]: invalid NUMA node %d for device %s", i, *device.NUMANode, device.ID)
+		}
+	}
+	return nil
+}
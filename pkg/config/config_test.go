@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// writeConfig 将配置内容写入临时文件并返回路径
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestLoad 测试解析完整的配置文件
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+resourceName: example.com/ppu
+deviceCount: 4
+socketPath: /tmp/device-plugins/
+logLevel: debug
+devices:
+  - id: ppu-1
+    health: Unhealthy
+  - id: ppu-2
+    numaNode: 1
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.ResourceName != "example.com/ppu" || cfg.DeviceCount != 4 || cfg.SocketPath != "/tmp/device-plugins/" || cfg.LogLevel != "debug" {
+		t.Errorf("Unexpected config values: %+v", cfg)
+	}
+	if len(cfg.Devices) != 2 {
+		t.Fatalf("Expected 2 device overrides, got %d", len(cfg.Devices))
+	}
+	if cfg.Devices[0].ID != "ppu-1" || cfg.Devices[0].Health != "Unhealthy" || cfg.Devices[0].NUMANode != nil {
+		t.Errorf("Unexpected override for ppu-1: %+v", cfg.Devices[0])
+	}
+	if cfg.Devices[1].NUMANode == nil || *cfg.Devices[1].NUMANode != 1 {
+		t.Errorf("Expected ppu-2 on NUMA node 1, got %+v", cfg.Devices[1])
+	}
+}
+
+// TestLoadDefaults 测试文件中未指定的字段使用默认值
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "deviceCount: 2\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.ResourceName != DefaultResourceName || cfg.SocketPath != DefaultSocketPath || cfg.LogLevel != DefaultLogLevel {
+		t.Errorf("Expected defaults for unset fields, got %+v", cfg)
+	}
+	if cfg.DeviceCount != 2 {
+		t.Errorf("Expected deviceCount 2, got %d", cfg.DeviceCount)
+	}
+}
+
+// TestLoadValidationErrors 测试非法配置返回错误
+func TestLoadValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"zero device count", "deviceCount: 0\n", "deviceCount must be greater than 0"},
+		{"negative device count", "deviceCount: -1\n", "deviceCount must be greater than 0"},
+		{"resource name without slash", "resourceName: ppu\n", "must be of the form"},
+		{"unknown field", "deviceCnt: 4\n", "field deviceCnt not found"},
+		{"invalid health", "devices:\n  - id: ppu-0\n    health: Broken\n", "invalid health"},
+		{"missing device id", "devices:\n  - health: Healthy\n", "id is required"},
+		{"duplicate device", "devices:\n  - id: ppu-0\n  - id: ppu-0\n", "duplicate override"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestLoadMissingFile 测试文件不存在时返回错误
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
+
+// TestResolveDeviceCountMismatch 测试命令行与配置文件设备数量不一致时以配置文件为准并记录警告
+func TestResolveDeviceCountMismatch(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "deviceCount: 4\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	if err := cfg.ResolveDeviceCount(8, false); err != nil {
+		t.Fatalf("ResolveDeviceCount failed: %v", err)
+	}
+	if cfg.DeviceCount != 4 {
+		t.Errorf("Expected config device count 4 to win, got %d", cfg.DeviceCount)
+	}
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != log.WarnLevel || !strings.Contains(entry.Message, "conflicts with deviceCount 4") {
+		t.Errorf("Expected a discrepancy warning, got %v", entry)
+	}
+
+	if err := cfg.ResolveDeviceCount(8, true); err == nil {
+		t.Error("Expected an error in strict mode")
+	}
+}
+
+// TestResolveDeviceCountUnset 测试配置文件未指定设备数量时使用命令行参数
+func TestResolveDeviceCountUnset(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "logLevel: debug\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if err := cfg.ResolveDeviceCount(8, true); err != nil {
+		t.Fatalf("ResolveDeviceCount failed: %v", err)
+	}
+	if cfg.DeviceCount != 8 {
+		t.Errorf("Expected flag device count 8, got %d", cfg.DeviceCount)
+	}
+}
+
+// TestLoadValidationErrorLocation 测试校验错误包含出错字段所在的行列
+func TestLoadValidationErrorLocation(t *testing.T) {
+	path := writeConfig(t, `resourceName: example.com/ppu
+deviceCount: 4
+devices:
+  - id: ppu-0
+    health: Healthy
+  - id: ppu-1
+    health: Broken
+`)
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Expected a validation error")
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected a *FieldError, got %T: %v", err, err)
+	}
+	if fieldErr.Field != "devices[1].health" || fieldErr.Line != 7 || fieldErr.Column != 13 {
+		t.Errorf("Expected devices[1].health at line 7, column 13, got %s at line %d, column %d",
+			fieldErr.Field, fieldErr.Line, fieldErr.Column)
+	}
+	if !strings.Contains(err.Error(), "line 7, column 13") {
+		t.Errorf("Expected error message to contain the location, got %v", err)
+	}
+}
+
+// TestLoadMissingIDLocation 测试缺少必填字段时定位到所在的列表项
+func TestLoadMissingIDLocation(t *testing.T) {
+	_, err := Load(writeConfig(t, "deviceCount: 2\ndevices:\n  - id: ppu-0\n  - health: Healthy\n"))
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected a *FieldError, got %v", err)
+	}
+	if fieldErr.Line != 4 {
+		t.Errorf("Expected the error on line 4, got %d", fieldErr.Line)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError 配置字段校验错误，从文件加载时包含字段在YAML中的位置
+type FieldError struct {
+	// Field 出错字段的路径，如devices[0].health
+	Field string
+	// Message 错误描述
+	Message string
+	// Line 字段所在行号，未知时为0
+	Line int
+	// Column 字段所在列号，未知时为0
+	Column int
+
+	// path 字段在YAML文档中的路径，元素为映射键(string)或序列下标(int)
+	path []interface{}
+}
+
+// Error 返回包含位置信息的错误描述
+func (e *FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return e.Message
+}
+
+// fieldError 创建字段校验错误，path依次为映射键或序列下标
+func fieldError(path []interface{}, format string, args ...interface{}) *FieldError {
+	var field strings.Builder
+	for _, element := range path {
+		switch v := element.(type) {
+		case int:
+			fmt.Fprintf(&field, "[%d]", v)
+		default:
+			if field.Len() > 0 {
+				field.WriteString(".")
+			}
+			fmt.Fprintf(&field, "%v", v)
+		}
+	}
+
+	return &FieldError{
+		Field:   field.String(),
+		Message: fmt.Sprintf(format, args...),
+		path:    path,
+	}
+}
+
+// locate 在YAML文档中查找字段位置，找不到完整路径时返回最深的已找到节点
+func (e *FieldError) locate(root *yaml.Node) {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, element := range e.path {
+		next := child(node, element)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	if node != nil && node.Kind != yaml.DocumentNode {
+		e.Line, e.Column = node.Line, node.Column
+	}
+}
+
+// child 返回映射节点中键对应的值节点或序列节点中下标对应的元素
+func child(node *yaml.Node, element interface{}) *yaml.Node {
+	switch key := element.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && key >= 0 && key < len(node.Content) {
+			return node.Content[key]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package deviceplugin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// Capabilities 描述当前运行实例启用的功能
+type Capabilities struct {
+	Metrics         bool     `json:"metrics"`
+	CDI             bool     `json:"cdi"`
+	Strategies      []string `json:"strategies"`
+	PowerStrategies []string `json:"powerStrategies"`
+	APIVersions     []string `json:"apiVersions"`
+}
+
+// Capabilities 根据插件的实际配置生成功能描述
+func (p *PPUDevicePlugin) Capabilities() Capabilities {
+	return Capabilities{
+		Metrics:         p.opts.MetricsAddr != "",
+		CDI:             p.opts.EnableCDI,
+		Strategies:      RegisteredStrategies(),
+		PowerStrategies: append([]string{}, PowerStrategies...),
+		APIVersions:     []string{v1beta1.Version},
+	}
+}
+
+// adminHandler 构建管理HTTP服务的路由，未启用Options.EnableAdminAPI时只提供/metrics和/readyz
+func (p *PPUDevicePlugin) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/readyz", p.handleReadyz)
+	mux.Handle("/metrics", p.metrics.handler())
+	if !p.opts.EnableAdminAPI {
+		return mux
+	}
+
+	mux.HandleFunc("/capabilities", p.handleCapabilities)
+	mux.HandleFunc("/info", p.handleInfo)
+	mux.HandleFunc("/devices", p.handleDevices)
+	mux.HandleFunc("POST /devices/{id}/health", p.handleDeviceHealth)
+	mux.HandleFunc("/history", p.handleHistory)
+	mux.HandleFunc("/history.csv", p.handleHistoryCSV)
+	mux.HandleFunc("/topology.dot", p.handleTopologyDOT)
+	mux.HandleFunc("/reserve", p.handleReserve(true))
+	mux.HandleFunc("/unreserve", p.handleReserve(false))
+	mux.HandleFunc("/reset", p.handleReset)
+	mux.HandleFunc("/maintenance", p.handleMaintenance)
+	mux.HandleFunc("/events", p.handleEvents)
+	return mux
+}
+
+// handleReadyz 就绪探针，插件就绪时返回200，否则返回503
+func (p *PPUDevicePlugin) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !p.Ready() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+// handleCapabilities 返回插件功能描述
+func (p *PPUDevicePlugin) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, p.log, http.StatusOK, p.Capabilities())
+}
+
+// handleDevices 返回当前的设备状态视图
+func (p *PPUDevicePlugin) handleDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, p.log, http.StatusOK, p.Snapshot())
+}
+
+// handleHistory 以JSON数组按时间顺序返回最近的分配记录
+func (p *PPUDevicePlugin) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, p.log, http.StatusOK, p.history.list())
+}
+
+// handleHistoryCSV 以CSV格式导出分配记录
+func (p *PPUDevicePlugin) handleHistoryCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"timestamp", "container", "devices", "outcome", "error"})
+	for _, record := range p.history.list() {
+		writer.Write([]string{
+			record.Time.Format(time.RFC3339Nano),
+			record.Container,
+			strings.Join(record.Devices, ","),
+			record.Outcome,
+			record.Error,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		p.log.Warnf("Failed to write allocation history CSV: %v", err)
+	}
+}
+
+// writeJSON 以JSON格式写入响应，编码失败时通过logger记录
+func writeJSON(w http.ResponseWriter, logger *log.Entry, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warnf("Failed to encode admin response: %v", err)
+	}
+}
+
+// startAdminServer 启动管理HTTP服务
+func (p *PPUDevicePlugin) startAdminServer() error {
+	if p.opts.MetricsAddr == "" {
+		p.log.Debug("Admin server disabled")
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", p.opts.MetricsAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on metrics address %s: %v", p.opts.MetricsAddr, err)
+	}
+
+	p.adminServer = &http.Server{
+		Handler:           p.adminHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		p.log.Infof("Admin server listening on %s", listener.Addr())
+		if !p.opts.EnableAdminAPI {
+			p.log.Info("Admin API disabled: serving only /metrics and /readyz")
+		}
+		if err := p.adminServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			p.log.Errorf("Admin server failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// stopAdminServer 关闭管理HTTP服务
+func (p *PPUDevicePlugin) stopAdminServer() {
+	if p.adminServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.adminServer.Shutdown(ctx); err != nil {
+		p.log.Warnf("Failed to shut down admin server: %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package deviceplugin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestCapabilitiesEndpoint 测试/capabilities返回实际启用的功能
+func TestCapabilitiesEndpoint(t *testing.T) {
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, t.TempDir(), Options{EnableAdminAPI: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var caps Capabilities
+	if err := json.Unmarshal(rec.Body.Bytes(), &caps); err != nil {
+		t.Fatalf("Failed to decode capabilities: %v", err)
+	}
+
+	if len(caps.APIVersions) != 1 || caps.APIVersions[0] != v1beta1.Version {
+		t.Errorf("Expected API versions [%s], got %v", v1beta1.Version, caps.APIVersions)
+	}
+	if len(caps.Strategies) == 0 || caps.Strategies[0] != "packed" {
+		t.Errorf("Expected packed strategy to be reported, got %v", caps.Strategies)
+	}
+	if !reflect.DeepEqual(caps.Strategies, RegisteredStrategies()) {
+		t.Errorf("Expected strategies %v, got %v", RegisteredStrategies(), caps.Strategies)
+	}
+	if !reflect.DeepEqual(caps.PowerStrategies, PowerStrategies) {
+		t.Errorf("Expected power strategies %v, got %v", PowerStrategies, caps.PowerStrategies)
+	}
+	if caps.Metrics || caps.CDI {
+		t.Errorf("Expected optional features disabled by default, got %+v", caps)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("Failed to decode capabilities: %v", err)
+	}
+	if _, exists := fields["tracing"]; exists {
+		t.Error("Expected capabilities not to report tracing, which has no config behind it")
+	}
+
+	enabled := NewPPUDevicePluginWithOptions("test.com/ppu", 2, t.TempDir(), Options{MetricsAddr: "127.0.0.1:0", EnableCDI: true})
+	if caps := enabled.Capabilities(); !caps.Metrics || !caps.CDI {
+		t.Errorf("Expected metrics and CDI capabilities when configured, got %+v", caps)
+	}
+}
+
+// TestHistoryCSVEndpoint 测试/history.csv导出已记录的分配
+func TestHistoryCSVEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{EnableAdminAPI: true})
+
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-0", "ppu-1"}},
+			{DevicesIDs: []string{"ppu-2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history.csv", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header and 2 records, got %d rows", len(rows))
+	}
+	if rows[0][0] != "timestamp" || rows[0][2] != "devices" {
+		t.Errorf("Unexpected header row: %v", rows[0])
+	}
+	if rows[1][1] != "allocation-1/container-0" || rows[1][2] != "ppu-0,ppu-1" || rows[1][3] != OutcomeSuccess {
+		t.Errorf("Unexpected first record: %v", rows[1])
+	}
+	if rows[2][1] != "allocation-1/container-1" || rows[2][2] != "ppu-2" {
+		t.Errorf("Unexpected second record: %v", rows[2])
+	}
+}
+
+// TestHistoryEndpoint 测试/history按顺序返回最近的分配记录，条数不超过配置的容量
+func TestHistoryEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{HistorySize: 3, EnableAdminAPI: true})
+	for _, deviceID := range []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"} {
+		allocate(t, plugin, deviceID)
+	}
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var records []AllocationRecord
+	if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+		t.Fatalf("Failed to decode history: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for i, deviceID := range []string{"ppu-1", "ppu-2", "ppu-3"} {
+		if !reflect.DeepEqual(records[i].Devices, []string{deviceID}) || records[i].Outcome != OutcomeSuccess {
+			t.Errorf("Expected record %d for %s, got %+v", i, deviceID, records[i])
+		}
+	}
+	if records[0].Time.After(records[2].Time) {
+		t.Errorf("Expected records in allocation order, got %v before %v", records[0].Time, records[2].Time)
+	}
+}
+
+// TestDevicesEndpoint 测试/devices返回所有设备及其健康、NUMA和分配状态
+func TestDevicesEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{NUMANodes: 2, EnableAdminAPI: true})
+	plugin.setDeviceHealth("ppu-2", v1beta1.Unhealthy)
+	allocate(t, plugin, "ppu-1")
+	if err := plugin.SetDeviceTemperature("ppu-0", 47.5); err != nil {
+		t.Fatalf("SetDeviceTemperature failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/devices", nil)
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode devices: %v", err)
+	}
+	if len(snapshot.Devices) != 3 {
+		t.Fatalf("Expected 3 devices, got %d", len(snapshot.Devices))
+	}
+
+	expected := []struct {
+		id        string
+		health    string
+		node      int64
+		allocated bool
+	}{
+		{"ppu-0", v1beta1.Healthy, 0, false},
+		{"ppu-1", v1beta1.Healthy, 1, true},
+		{"ppu-2", v1beta1.Unhealthy, 0, false},
+	}
+	for i, want := range expected {
+		got := snapshot.Devices[i]
+		if got.ID != want.id || got.Health != want.health || got.Allocated != want.allocated {
+			t.Errorf("Device %d: expected %+v, got %+v", i, want, got)
+		}
+		if got.NUMANode == nil || *got.NUMANode != want.node {
+			t.Errorf("Device %s: expected NUMA node %d, got %v", got.ID, want.node, got.NUMANode)
+		}
+	}
+	if got := snapshot.Devices[0].Temperature; got != 47.5 {
+		t.Errorf("Expected ppu-0 temperature 47.5, got %v", got)
+	}
+}
+
+// TestReadyzEndpoint 测试/readyz在启动前返回503，启动成功后返回200，停止后恢复为503
+func TestReadyzEndpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	startFakeKubelet(t, tmpDir)
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+
+	readyz := func() int {
+		rec := httptest.NewRecorder()
+		plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before Start, got %d", code)
+	}
+
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if code := readyz(); code != http.StatusOK {
+		t.Errorf("Expected 200 after Start, got %d", code)
+	}
+
+	plugin.Stop()
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 after Stop, got %d", code)
+	}
+}
+
+// TestInfoEndpoint 测试/info返回构建信息和声明的API版本
+func TestInfoEndpoint(t *testing.T) {
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 1, t.TempDir(), Options{
+		EnableAdminAPI: true,
+		BuildInfo:      BuildInfo{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2024-01-02T03:04:05Z"},
+	})
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var info map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Failed to decode info: %v", err)
+	}
+
+	expected := map[string]string{
+		"version":      "v1.2.3",
+		"commit":       "abc1234",
+		"buildDate":    "2024-01-02T03:04:05Z",
+		"apiVersion":   v1beta1.Version,
+		"resourceName": "test.com/ppu",
+	}
+	for key, want := range expected {
+		if got := info[key]; got != want {
+			t.Errorf("Expected %s %q, got %q", key, want, got)
+		}
+	}
+	if info["goVersion"] == "" {
+		t.Error("Expected goVersion to be set")
+	}
+	if len(info) != len(expected)+1 {
+		t.Errorf("Unexpected info fields: %v", info)
+	}
+}
+
+// TestAdminAPIDisabledByDefault 测试默认只提供/metrics和/readyz，其余管理接口需显式启用
+func TestAdminAPIDisabledByDefault(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+	plugin.ready.Store(true)
+	handler := plugin.adminHandler()
+
+	for _, path := range []string{"/metrics", "/readyz"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected GET %s to return 200, got %d", path, rec.Code)
+		}
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/devices", nil),
+		httptest.NewRequest(http.MethodPost, "/reset", nil),
+		httptest.NewRequest(http.MethodPost, "/reserve", strings.NewReader(`["ppu-0"]`)),
+		httptest.NewRequest(http.MethodPost, "/maintenance", strings.NewReader(`{"enabled":true}`)),
+		httptest.NewRequest(http.MethodPost, "/devices/ppu-0/health", strings.NewReader(`{"health":"Unhealthy"}`)),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected %s %s to be disabled, got %d", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+	if plugin.Maintenance() {
+		t.Error("Expected disabled admin API not to change maintenance mode")
+	}
+}
@@ -0,0 +1,43 @@
+package deviceplugin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultAnnotationPrefix 默认的分配响应注解命名空间
+const DefaultAnnotationPrefix = "ppu.alibabacloud.com"
+
+// annotationKey 返回注解命名空间下的注解名
+func (p *PPUDevicePlugin) annotationKey(name string) string {
+	prefix := p.opts.AnnotationPrefix
+	if prefix == "" {
+		prefix = DefaultAnnotationPrefix
+	}
+	return prefix + "/" + name
+}
+
+// allocationAnnotations 构建容器分配响应的注解：分配的设备列表，以及按相同顺序排列的各设备NUMA节点
+func (p *PPUDevicePlugin) allocationAnnotations(deviceIDs []string) map[string]string {
+	annotations := map[string]string{
+		p.annotationKey("allocated-devices"): strings.Join(deviceIDs, ","),
+	}
+
+	nodes := make([]string, 0, len(deviceIDs))
+	p.mu.RLock()
+	for _, deviceID := range deviceIDs {
+		device, ok := p.devices[deviceID]
+		if !ok || device.Topology == nil || len(device.Topology.Nodes) == 0 {
+			continue
+		}
+		nodes = append(nodes, strconv.FormatInt(device.Topology.Nodes[0].ID, 10))
+	}
+	p.mu.RUnlock()
+
+	// 仅在所有设备都有拓扑信息时输出，保证与设备列表一一对应
+	if len(deviceIDs) > 0 && len(nodes) == len(deviceIDs) {
+		annotations[p.annotationKey("numa-node")] = strings.Join(nodes, ",")
+	}
+	p.memoryAnnotations(annotations)
+	return annotations
+}
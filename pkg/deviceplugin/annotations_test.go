@@ -0,0 +1,45 @@
+package deviceplugin
+
+import "testing"
+
+// TestAllocationAnnotations 测试分配响应的NUMA注解与设备的拓扑一致，并可修改注解命名空间
+func TestAllocationAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		deviceID string
+		wantNode string
+	}{
+		{name: "default prefix", deviceID: "ppu-1", wantNode: "1"},
+		{name: "custom prefix", prefix: "example.com", deviceID: "ppu-2", wantNode: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, 4, Options{NUMANodes: 2, AnnotationPrefix: tt.prefix})
+
+			prefix := tt.prefix
+			if prefix == "" {
+				prefix = DefaultAnnotationPrefix
+			}
+
+			annotations := allocate(t, plugin, tt.deviceID).ContainerResponses[0].Annotations
+			if got := annotations[prefix+"/numa-node"]; got != tt.wantNode {
+				t.Errorf("Expected %s/numa-node %q, got %q", prefix, tt.wantNode, got)
+			}
+			if got := annotations[prefix+"/allocated-devices"]; got != tt.deviceID {
+				t.Errorf("Expected %s/allocated-devices %q, got %q", prefix, tt.deviceID, got)
+			}
+		})
+	}
+}
+
+// TestAllocationAnnotationsWithoutTopology 测试未上报拓扑时不输出NUMA注解
+func TestAllocationAnnotationsWithoutTopology(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	annotations := allocate(t, plugin, "ppu-0").ContainerResponses[0].Annotations
+	if _, ok := annotations[DefaultAnnotationPrefix+"/numa-node"]; ok {
+		t.Errorf("Expected no NUMA annotation without topology, got %v", annotations)
+	}
+}
@@ -0,0 +1,70 @@
+package deviceplugin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DeviceAttributes 设备的元数据，分配时以环境变量的形式传入容器
+type DeviceAttributes struct {
+	// Model 设备型号，为空时不注入
+	Model string
+	// MemoryMB 设备显存大小（MB），为零时不注入
+	MemoryMB int
+}
+
+// DeviceAttributes 返回设备的元数据
+func (p *PPUDevicePlugin) DeviceAttributes(deviceID string) (DeviceAttributes, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	attributes, exists := p.attributes[deviceID]
+	return attributes, exists
+}
+
+// deviceAttributes 生成设备的初始元数据，设备覆盖中的配置优先于全局配置
+func (p *PPUDevicePlugin) deviceAttributes(deviceID string) DeviceAttributes {
+	attributes := DeviceAttributes{Model: p.opts.DeviceModel, MemoryMB: p.opts.DeviceMemoryMB}
+	if override, ok := p.opts.DeviceOverrides[deviceID]; ok {
+		if override.Model != "" {
+			attributes.Model = override.Model
+		}
+		if override.MemoryMB > 0 {
+			attributes.MemoryMB = override.MemoryMB
+		}
+	}
+	return attributes
+}
+
+// attributeEnvs 为每个分配的设备生成元数据环境变量，如ppu-0对应PPU_0_MODEL和PPU_0_MEMORY_MB
+func (p *PPUDevicePlugin) attributeEnvs(deviceIDs []string) map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	envs := make(map[string]string)
+	for _, deviceID := range deviceIDs {
+		attributes := p.attributes[deviceID]
+		prefix := attributeEnvPrefix(deviceID)
+		if attributes.Model != "" {
+			envs[prefix+"_MODEL"] = attributes.Model
+		}
+		if attributes.MemoryMB > 0 {
+			envs[prefix+"_MEMORY_MB"] = strconv.Itoa(attributes.MemoryMB)
+		}
+	}
+	return envs
+}
+
+// attributeEnvPrefix 将设备ID转换为合法的环境变量前缀，去掉ppu-前缀后转为大写，非字母数字字符替换为下划线
+func attributeEnvPrefix(deviceID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(deviceID, DeviceIDPrefix))
+	return "PPU_" + name
+}
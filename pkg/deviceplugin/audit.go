@@ -0,0 +1,68 @@
+package deviceplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// auditRecord 审计日志中的一条分配记录，每个容器一行JSON
+type auditRecord struct {
+	Time           time.Time `json:"time"`
+	Resource       string    `json:"resource"`
+	AllocationID   uint64    `json:"allocationId"`
+	ContainerIndex int       `json:"containerIndex"`
+	Devices        []string  `json:"devices"`
+}
+
+// writeAuditLog 将一次成功分配的各容器设备追加到审计日志，未配置审计日志时不做任何事
+func (p *PPUDevicePlugin) writeAuditLog(allocationID uint64, containerDevices [][]string) {
+	if p.opts.AuditLog == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i, devices := range containerDevices {
+		record := auditRecord{
+			Time:           now,
+			Resource:       p.resourceName,
+			AllocationID:   allocationID,
+			ContainerIndex: i,
+			Devices:        devices,
+		}
+		if err := encoder.Encode(record); err != nil {
+			p.log.Warnf("Failed to encode audit record: %v", err)
+			return
+		}
+	}
+
+	// 同一次分配的记录一次写入，避免与并发的分配交错
+	p.auditMu.Lock()
+	defer p.auditMu.Unlock()
+
+	if err := appendFile(p.opts.AuditLog, buf.Bytes()); err != nil {
+		p.log.Warnf("Failed to write audit log: %v", err)
+	}
+}
+
+// appendFile 以追加方式写入文件，文件或目录不存在时创建
+func appendFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append audit log: %v", err)
+	}
+	return f.Close()
+}
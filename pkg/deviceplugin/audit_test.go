@@ -0,0 +1,51 @@
+package deviceplugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestAuditLog 测试每次Allocate按容器向审计日志追加JSON行
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "allocations.jsonl")
+	plugin := newTestPlugin(t, 4, Options{AuditLog: path})
+
+	before := time.Now().UTC()
+	allocate(t, plugin, "ppu-0", "ppu-1")
+	allocate(t, plugin, "ppu-3")
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer f.Close()
+
+	records := []auditRecord{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(records))
+	}
+	first := records[0]
+	if !reflect.DeepEqual(first.Devices, []string{"ppu-0", "ppu-1"}) || first.ContainerIndex != 0 || first.Resource != "test.com/ppu" {
+		t.Errorf("Unexpected first audit record: %+v", first)
+	}
+	if first.Time.Before(before.Truncate(time.Second)) {
+		t.Errorf("Expected audit timestamp after %s, got %s", before, first.Time)
+	}
+	if records[1].AllocationID <= first.AllocationID || !reflect.DeepEqual(records[1].Devices, []string{"ppu-3"}) {
+		t.Errorf("Unexpected second audit record: %+v", records[1])
+	}
+}
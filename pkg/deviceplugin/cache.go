@@ -0,0 +1,137 @@
+package deviceplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// cachedAllocation 缓存的容器分配结果
+type cachedAllocation struct {
+	// requested 容器请求的全部设备ID，其中任一设备释放或健康状态变化时缓存失效
+	requested []string
+	// devices 实际分配的设备ID
+	devices []string
+	// owner 首次分配时记录的分配对象，设备被其他对象持有时缓存不再有效
+	owner    string
+	response *v1beta1.ContainerAllocateResponse
+}
+
+// allocationSignature 返回容器请求的签名：排序后的设备ID的哈希，与请求中的设备顺序无关
+func allocationSignature(deviceIDs []string) string {
+	sorted := append([]string{}, deviceIDs...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedAllocationFor 返回签名对应的缓存分配结果的副本及其分配对象，未启用缓存、未命中或缓存已失效时返回false；
+// 缓存的设备须未被分配或仍由原分配对象持有，且未被预留、未损坏，否则删除该条目
+func (p *PPUDevicePlugin) cachedAllocationFor(signature string) (*v1beta1.ContainerAllocateResponse, []string, string, bool) {
+	if !p.opts.CacheAllocations {
+		return nil, nil, "", false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.allocationCache[signature]
+	if !ok {
+		return nil, nil, "", false
+	}
+	if reason := p.cacheEntryConflictLocked(entry); reason != "" {
+		p.log.Infof("Dropping cached allocation of %v: %s", entry.devices, reason)
+		delete(p.allocationCache, signature)
+		return nil, nil, "", false
+	}
+	return cloneContainerResponse(entry.response), append([]string{}, entry.devices...), entry.owner, true
+}
+
+// cacheEntryConflictLocked 返回缓存条目不能再使用的原因，仍然有效时返回空字符串，调用方需持有p.mu
+func (p *PPUDevicePlugin) cacheEntryConflictLocked(entry *cachedAllocation) string {
+	for _, deviceID := range entry.devices {
+		if holder, taken := p.allocated[deviceID]; taken && holder != entry.owner {
+			return "device " + deviceID + " is allocated to " + holder
+		}
+		if _, exists := p.devices[deviceID]; !exists {
+			return "device " + deviceID + " no longer exists"
+		}
+		if p.reserved[deviceID] {
+			return "device " + deviceID + " is reserved"
+		}
+		if p.permanentlyDead[deviceID] {
+			return "device " + deviceID + " is permanently dead"
+		}
+	}
+	return ""
+}
+
+// cacheAllocation 缓存容器分配结果的副本，未启用缓存时不做处理
+func (p *PPUDevicePlugin) cacheAllocation(requested, devices []string, owner string, response *v1beta1.ContainerAllocateResponse) {
+	if !p.opts.CacheAllocations {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.allocationCache[allocationSignature(requested)] = &cachedAllocation{
+		requested: append([]string{}, requested...),
+		devices:   append([]string{}, devices...),
+		owner:     owner,
+		response:  cloneContainerResponse(response),
+	}
+}
+
+// invalidateAllocationCacheLocked 删除请求中包含任一给定设备的缓存条目，调用方需持有p.mu
+func (p *PPUDevicePlugin) invalidateAllocationCacheLocked(deviceIDs ...string) {
+	if len(p.allocationCache) == 0 {
+		return
+	}
+
+	affected := make(map[string]bool, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		affected[deviceID] = true
+	}
+	for signature, entry := range p.allocationCache {
+		for _, deviceID := range entry.requested {
+			if affected[deviceID] {
+				delete(p.allocationCache, signature)
+				break
+			}
+		}
+	}
+}
+
+// cloneContainerResponse 深拷贝容器分配响应，避免调用方修改缓存的内容
+func cloneContainerResponse(response *v1beta1.ContainerAllocateResponse) *v1beta1.ContainerAllocateResponse {
+	clone := &v1beta1.ContainerAllocateResponse{
+		Envs:        make(map[string]string, len(response.Envs)),
+		Annotations: make(map[string]string, len(response.Annotations)),
+	}
+	for key, value := range response.Envs {
+		clone.Envs[key] = value
+	}
+	for key, value := range response.Annotations {
+		clone.Annotations[key] = value
+	}
+	for _, mount := range response.Mounts {
+		m := *mount
+		clone.Mounts = append(clone.Mounts, &m)
+	}
+	if response.Devices != nil {
+		clone.Devices = make([]*v1beta1.DeviceSpec, 0, len(response.Devices))
+	}
+	for _, spec := range response.Devices {
+		s := *spec
+		clone.Devices = append(clone.Devices, &s)
+	}
+	for _, device := range response.CDIDevices {
+		d := *device
+		clone.CDIDevices = append(clone.CDIDevices, &d)
+	}
+	return clone
+}
@@ -0,0 +1,109 @@
+package deviceplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestAllocationCacheIdenticalResponses 测试重复的Allocate请求返回字节一致的缓存响应
+func TestAllocationCacheIdenticalResponses(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{CacheAllocations: true, NUMANodes: 2, DevicePathTemplate: "/dev/ppu%d"})
+
+	first := allocate(t, plugin, "ppu-1", "ppu-0")
+	second := allocate(t, plugin, "ppu-0", "ppu-1")
+
+	// protobuf编码中map的顺序不固定，使用按键排序的JSON编码比较
+	a, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("Failed to marshal first response: %v", err)
+	}
+	b, err := json.Marshal(second)
+	if err != nil {
+		t.Fatalf("Failed to marshal second response: %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("Expected identical responses, got %v and %v", first, second)
+	}
+
+	// 修改返回的响应不影响缓存内容
+	second.ContainerResponses[0].Envs["PPU_DEVICE_COUNT"] = "99"
+	third := allocate(t, plugin, "ppu-0", "ppu-1")
+	if got := third.ContainerResponses[0].Envs["PPU_DEVICE_COUNT"]; got != "2" {
+		t.Errorf("Expected cached response to be isolated from callers, got count %s", got)
+	}
+}
+
+// TestAllocationCacheInvalidation 测试设备释放或健康状态变化后缓存失效
+func TestAllocationCacheInvalidation(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{CacheAllocations: true})
+
+	allocate(t, plugin, "ppu-0")
+	plugin.applyHealthEvent(DeviceHealthEvent{ID: "ppu-0", Health: v1beta1.Unhealthy})
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected health change to invalidate the cached allocation, got %v", err)
+	}
+
+	allocate(t, plugin, "ppu-1")
+	plugin.releaseDevices([]string{"ppu-1"})
+	if _, cached := plugin.allocationCache[allocationSignature([]string{"ppu-1"})]; cached {
+		t.Error("Expected release to invalidate the cached allocation")
+	}
+}
+
+// TestAllocationCacheDisabled 测试未启用缓存时重复分配同一设备被拒绝
+func TestAllocationCacheDisabled(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	allocate(t, plugin, "ppu-0")
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted without the cache, got %v", err)
+	}
+}
+
+// TestAllocationCacheHeldByOtherPod 测试缓存的设备被其他分配对象持有时不返回缓存的响应，而是拒绝分配
+func TestAllocationCacheHeldByOtherPod(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{CacheAllocations: true})
+
+	allocate(t, plugin, "ppu-0")
+
+	// 同一请求中的另一个容器不能通过缓存再次分到同一设备
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-0"}},
+			{DevicesIDs: []string{"ppu-0"}},
+		},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted for two containers sharing a cached device, got %v", err)
+	}
+
+	// 模拟设备已被另一个Pod持有
+	plugin.mu.Lock()
+	plugin.allocated["ppu-0"] = "allocation-99/container-0"
+	plugin.mu.Unlock()
+
+	_, err = plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted while another pod holds the cached device, got %v", err)
+	}
+	if _, cached := plugin.allocationCache[allocationSignature([]string{"ppu-0"})]; cached {
+		t.Error("Expected the stale cached allocation to be dropped")
+	}
+	if owner := plugin.allocated["ppu-0"]; owner != "allocation-99/container-0" {
+		t.Errorf("Expected ppu-0 to stay with its holder, got %s", owner)
+	}
+}
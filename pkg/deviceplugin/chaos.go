@@ -0,0 +1,137 @@
+package deviceplugin
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// pickUnhealthyDevices 根据配置的设备列表和比例选出启动时即不健康的设备
+func (p *PPUDevicePlugin) pickUnhealthyDevices(deviceIDs []string) map[string]bool {
+	unhealthy := make(map[string]bool)
+
+	known := make(map[string]bool, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		known[deviceID] = true
+	}
+	for _, deviceID := range p.opts.UnhealthyDevices {
+		if !known[deviceID] {
+			p.log.Warnf("Configured unhealthy device %s does not exist, ignoring", deviceID)
+			continue
+		}
+		unhealthy[deviceID] = true
+	}
+
+	if p.opts.UnhealthyRatio > 0 {
+		count := int(math.Round(p.opts.UnhealthyRatio * float64(len(deviceIDs))))
+		p.mu.Lock()
+		perm := p.rng.Perm(len(deviceIDs))
+		p.mu.Unlock()
+		for _, i := range perm[:count] {
+			unhealthy[deviceIDs[i]] = true
+		}
+	}
+
+	return unhealthy
+}
+
+// injectUnhealthyDevices 将选中的设备标记为不健康，健康检查不会自动恢复这些设备
+func (p *PPUDevicePlugin) injectUnhealthyDevices(deviceIDs []string) {
+	unhealthy := p.pickUnhealthyDevices(deviceIDs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stickyUnhealthy = unhealthy
+	for deviceID := range unhealthy {
+		p.devices[deviceID].Health = v1beta1.Unhealthy
+		p.log.Infof("Injected unhealthy state for device %s", deviceID)
+	}
+	p.updateDeviceGaugesLocked()
+}
+
+// markDeadDevices 将配置的设备标记为永久损坏
+func (p *PPUDevicePlugin) markDeadDevices(deviceIDs []string) {
+	known := make(map[string]bool, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		known[deviceID] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, deviceID := range p.opts.DeadDevices {
+		if !known[deviceID] {
+			p.log.Warnf("Configured dead device %s does not exist, ignoring", deviceID)
+			continue
+		}
+		p.permanentlyDead[deviceID] = true
+		p.devices[deviceID].Health = v1beta1.Unhealthy
+		p.log.Infof("Marked device %s as permanently dead", deviceID)
+	}
+	p.updateDeviceGaugesLocked()
+}
+
+// deviceDead 判断设备是否永久损坏
+func (p *PPUDevicePlugin) deviceDead(deviceID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.permanentlyDead[deviceID]
+}
+
+// chaosSeed 返回插件随机数生成器的种子：确定性模式下始终使用RNGSeed（包括零），
+// 否则ChaosSeed优先于RNGSeed，均未配置时使用当前时间
+func chaosSeed(opts Options) int64 {
+	if opts.Deterministic {
+		return opts.RNGSeed
+	}
+	if opts.ChaosSeed != 0 {
+		return opts.ChaosSeed
+	}
+	if opts.RNGSeed != 0 {
+		return opts.RNGSeed
+	}
+	return time.Now().UnixNano()
+}
+
+// simulateAllocateFailure 按AllocateFailureRate掷骰，返回本次Allocate是否应模拟失败
+func (p *PPUDevicePlugin) simulateAllocateFailure() bool {
+	if p.opts.AllocateFailureRate <= 0 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.rng.Float64() < p.opts.AllocateFailureRate
+}
+
+// rollDeviceFailures 按各设备配置的故障概率掷骰，返回本周期变为不健康的健康设备
+func (p *PPUDevicePlugin) rollDeviceFailures() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 按固定顺序掷骰，保证相同种子下结果可复现
+	ids := make([]string, 0, len(p.opts.DeviceOverrides))
+	for deviceID, override := range p.opts.DeviceOverrides {
+		if override.FailureProbability > 0 {
+			ids = append(ids, deviceID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+
+	failed := []string{}
+	for _, deviceID := range ids {
+		device, exists := p.devices[deviceID]
+		if !exists || device.Health != v1beta1.Healthy {
+			continue
+		}
+		if p.rng.Float64() < p.opts.DeviceOverrides[deviceID].FailureProbability {
+			failed = append(failed, deviceID)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,66 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// randomTrace 依次执行依赖随机数的操作，记录健康状态、设备顺序、故障注入、温度变化和分配结果
+func randomTrace(t *testing.T, plugin *PPUDevicePlugin) []string {
+	t.Helper()
+
+	trace := []string{}
+	for _, device := range plugin.Snapshot().Devices {
+		trace = append(trace, fmt.Sprintf("init %s %s %.3f", device.ID, device.Health, device.Temperature))
+	}
+	for i := 0; i < 20; i++ {
+		trace = append(trace, fmt.Sprintf("order %v", deviceIDs(plugin)))
+		trace = append(trace, fmt.Sprintf("failures %v", plugin.rollDeviceFailures()))
+		trace = append(trace, fmt.Sprintf("interval %s", plugin.jitteredInterval(time.Second)))
+
+		plugin.driftTemperatures()
+		for _, device := range plugin.Snapshot().Devices {
+			trace = append(trace, fmt.Sprintf("temperature %s %.6f", device.ID, device.Temperature))
+		}
+
+		_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+			ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+		})
+		trace = append(trace, fmt.Sprintf("allocate %v", err))
+		plugin.releaseDevices([]string{"ppu-0"})
+	}
+	return trace
+}
+
+// TestDeterministicMode 测试确定性模式下相同种子的两个插件产生完全相同的健康和分配序列
+func TestDeterministicMode(t *testing.T) {
+	opts := Options{
+		Deterministic:       true,
+		RNGSeed:             2024,
+		ChaosSeed:           1,
+		UnhealthyRatio:      0.25,
+		ShuffleDevices:      true,
+		HealthJitter:        0.2,
+		AllocateFailureRate: 0.5,
+		DeviceOverrides: map[string]DeviceOverride{
+			"ppu-1": {FailureProbability: 0.5},
+			"ppu-2": {FailureProbability: 0.5},
+		},
+	}
+
+	first := randomTrace(t, newTestPlugin(t, 8, opts))
+	second := randomTrace(t, newTestPlugin(t, 8, opts))
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("Expected identical runs with the same seed:\n%v\n%v", first, second)
+	}
+
+	opts.RNGSeed = 2025
+	if other := randomTrace(t, newTestPlugin(t, 8, opts)); reflect.DeepEqual(first, other) {
+		t.Error("Expected a different seed to produce a different run")
+	}
+}
@@ -0,0 +1,67 @@
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// SetDeviceHealth 手动设置设备的健康状态并立即通知ListAndWatch。手动标记为不健康的设备不会被健康检查自动恢复，
+// 直到再次手动设置为健康；永久损坏的设备不能恢复
+func (p *PPUDevicePlugin) SetDeviceHealth(deviceID, health string) error {
+	if health != v1beta1.Healthy && health != v1beta1.Unhealthy {
+		return fmt.Errorf("invalid health %q: must be %s or %s", health, v1beta1.Healthy, v1beta1.Unhealthy)
+	}
+
+	p.mu.Lock()
+	if _, exists := p.devices[deviceID]; !exists {
+		p.mu.Unlock()
+		return fmt.Errorf("unknown device %s", deviceID)
+	}
+	if p.permanentlyDead[deviceID] && health == v1beta1.Healthy {
+		p.mu.Unlock()
+		return fmt.Errorf("device %s is permanently dead and cannot be marked healthy", deviceID)
+	}
+	if p.stickyUnhealthy == nil {
+		p.stickyUnhealthy = make(map[string]bool)
+	}
+	if health == v1beta1.Unhealthy {
+		p.stickyUnhealthy[deviceID] = true
+	} else {
+		delete(p.stickyUnhealthy, deviceID)
+	}
+	p.mu.Unlock()
+
+	p.log.Infof("Setting device %s health to %s", deviceID, health)
+	p.applyHealthEvent(DeviceHealthEvent{ID: deviceID, Health: health})
+	return nil
+}
+
+// deviceHealthRequest POST /devices/{id}/health的请求体
+type deviceHealthRequest struct {
+	Health string `json:"health"`
+}
+
+// handleDeviceHealth 处理POST /devices/{id}/health，请求体为{"health": "Unhealthy"}，设备不存在时返回404
+func (p *PPUDevicePlugin) handleDeviceHealth(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+	if _, exists := p.deviceHealth(deviceID); !exists {
+		http.Error(w, fmt.Sprintf("unknown device %s", deviceID), http.StatusNotFound)
+		return
+	}
+
+	var request deviceHealthRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := p.SetDeviceHealth(deviceID, request.Health); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	health, _ := p.deviceHealth(deviceID)
+	writeJSON(w, p.log, http.StatusOK, map[string]string{"id": deviceID, "health": health})
+}
@@ -0,0 +1,45 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestDeviceHealthEndpoint 测试通过POST /devices/{id}/health翻转设备健康状态后ListAndWatch立即上报变化
+func TestDeviceHealthEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{EnableAdminAPI: true})
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		plugin.adminHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := post("/devices/ppu-1/health", `{"health":"Unhealthy"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	response := stream.next(t)
+	for _, device := range response.Devices {
+		expected := v1beta1.Healthy
+		if device.ID == "ppu-1" {
+			expected = v1beta1.Unhealthy
+		}
+		if device.Health != expected {
+			t.Errorf("Expected %s to be %s, got %s", device.ID, expected, device.Health)
+		}
+	}
+
+	if recorder := post("/devices/ppu-9/health", `{"health":"Unhealthy"}`); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown device, got %d", recorder.Code)
+	}
+	if recorder := post("/devices/ppu-0/health", `{"health":"Broken"}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid health value, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package deviceplugin
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DeviceIDFormatIndex 设备ID为前缀加序号（如ppu-0），默认格式
+	DeviceIDFormatIndex = "index"
+	// DeviceIDFormatUUID 设备ID为按序号和种子确定性生成的UUID
+	DeviceIDFormatUUID = "uuid"
+)
+
+// uuidPattern 匹配标准格式的UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ValidateDeviceIDFormat 校验设备ID格式：index、uuid，或恰好包含一个%d的自定义模板（如gpu-%d）
+func ValidateDeviceIDFormat(format string) error {
+	switch format {
+	case "", DeviceIDFormatIndex, DeviceIDFormatUUID:
+		return nil
+	}
+
+	if strings.Count(format, "%") != 1 || strings.Count(format, "%d") != 1 {
+		return fmt.Errorf("invalid device ID format %q: must be %s, %s or a template with exactly one %%d",
+			format, DeviceIDFormatIndex, DeviceIDFormatUUID)
+	}
+	return nil
+}
+
+// deviceIDForIndex 按配置的设备ID格式生成序号为index的物理设备ID
+func (p *PPUDevicePlugin) deviceIDForIndex(index int) string {
+	switch p.opts.DeviceIDFormat {
+	case "", DeviceIDFormatIndex:
+		return fmt.Sprintf("%s%d", DeviceIDPrefix, index)
+	case DeviceIDFormatUUID:
+		return deviceUUID(p.opts.DeviceIDSeed, index)
+	default:
+		return fmt.Sprintf(p.opts.DeviceIDFormat, index)
+	}
+}
+
+// deviceUUID 根据种子和序号生成确定性的UUID（版本5格式），相同输入始终得到相同的ID
+func deviceUUID(seed int64, index int) string {
+	var input [16]byte
+	binary.BigEndian.PutUint64(input[:8], uint64(seed))
+	binary.BigEndian.PutUint64(input[8:], uint64(index))
+	sum := sha1.Sum(input[:])
+
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// matchesDeviceIDFormat 判断设备ID是否符合配置的ID格式，同时返回用于日志和错误信息的格式描述
+func (p *PPUDevicePlugin) matchesDeviceIDFormat(deviceID string) (bool, string) {
+	switch p.opts.DeviceIDFormat {
+	case "", DeviceIDFormatIndex:
+		return strings.HasPrefix(deviceID, DeviceIDPrefix), fmt.Sprintf("prefix %q", DeviceIDPrefix)
+	case DeviceIDFormatUUID:
+		return uuidPattern.MatchString(deviceID), "format " + DeviceIDFormatUUID
+	default:
+		prefix := p.opts.DeviceIDFormat[:strings.Index(p.opts.DeviceIDFormat, "%d")]
+		return strings.HasPrefix(deviceID, prefix), fmt.Sprintf("prefix %q", prefix)
+	}
+}
@@ -0,0 +1,136 @@
+package deviceplugin
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// pluginDeviceIDs 返回插件当前全部设备ID，按自然顺序排序
+func pluginDeviceIDs(plugin *PPUDevicePlugin) []string {
+	ids := make([]string, 0, len(plugin.devices))
+	for deviceID := range plugin.devices {
+		ids = append(ids, deviceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+	return ids
+}
+
+// TestDeviceIDFormats 测试各设备ID格式生成的ID，以及分配和ListAndWatch均使用生成的ID
+func TestDeviceIDFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		check  func(deviceID string) bool
+	}{
+		{name: "default", format: "", check: func(id string) bool { return strings.HasPrefix(id, DeviceIDPrefix) }},
+		{name: "index", format: DeviceIDFormatIndex, check: func(id string) bool { return strings.HasPrefix(id, DeviceIDPrefix) }},
+		{name: "uuid", format: DeviceIDFormatUUID, check: uuidPattern.MatchString},
+		{name: "template", format: "gpu-%d", check: func(id string) bool { return strings.HasPrefix(id, "gpu-") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, 3, Options{DeviceIDFormat: tt.format, StrictDeviceIDs: true})
+			ids := pluginDeviceIDs(plugin)
+			if len(ids) != 3 {
+				t.Fatalf("Expected 3 devices, got %v", ids)
+			}
+			for _, deviceID := range ids {
+				if !tt.check(deviceID) {
+					t.Errorf("Device ID %s does not match format %q", deviceID, tt.format)
+				}
+			}
+
+			response := allocate(t, plugin, ids[0])
+			if got := response.ContainerResponses[0].Envs["PPU_ALLOCATED_DEVICES"]; got != ids[0] {
+				t.Errorf("Expected %s to be allocated, got %q", ids[0], got)
+			}
+
+			stream := runListAndWatch(t, plugin)
+			if got := len(stream.next(t).Devices); got != 3 {
+				t.Errorf("Expected ListAndWatch to advertise 3 devices, got %d", got)
+			}
+		})
+	}
+}
+
+// TestDeviceIDTemplate 测试自定义模板按序号生成设备ID
+func TestDeviceIDTemplate(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{DeviceIDFormat: "accel%d"})
+	ids := pluginDeviceIDs(plugin)
+	if len(ids) != 2 || ids[0] != "accel0" || ids[1] != "accel1" {
+		t.Errorf("Expected [accel0 accel1], got %v", ids)
+	}
+}
+
+// TestDeviceUUIDDeterministic 测试相同种子在重启后生成相同的UUID，不同种子生成不同的UUID
+func TestDeviceUUIDDeterministic(t *testing.T) {
+	first := pluginDeviceIDs(newTestPlugin(t, 4, Options{DeviceIDFormat: DeviceIDFormatUUID, DeviceIDSeed: 42}))
+	second := pluginDeviceIDs(newTestPlugin(t, 4, Options{DeviceIDFormat: DeviceIDFormatUUID, DeviceIDSeed: 42}))
+	other := pluginDeviceIDs(newTestPlugin(t, 4, Options{DeviceIDFormat: DeviceIDFormatUUID, DeviceIDSeed: 7}))
+
+	if strings.Join(first, ",") != strings.Join(second, ",") {
+		t.Errorf("Expected identical IDs for the same seed, got %v and %v", first, second)
+	}
+	for _, deviceID := range other {
+		for _, existing := range first {
+			if deviceID == existing {
+				t.Errorf("Expected different seeds to produce different IDs, both produced %s", deviceID)
+			}
+		}
+	}
+	if got := deviceUUID(42, 0); got != deviceUUID(42, 0) || got == deviceUUID(42, 1) {
+		t.Errorf("Expected deviceUUID to be stable per index, got %s", got)
+	}
+}
+
+// TestDeviceIDFormatDevicePath 测试ID不包含序号时设备路径模板仍使用设备序号
+func TestDeviceIDFormatDevicePath(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{DeviceIDFormat: DeviceIDFormatUUID, DevicePathTemplate: "/dev/ppu%d"})
+	deviceID := plugin.deviceIDForIndex(1)
+
+	response := allocate(t, plugin, deviceID)
+	specs := response.ContainerResponses[0].Devices
+	if len(specs) != 1 || specs[0].HostPath != "/dev/ppu1" {
+		t.Errorf("Expected /dev/ppu1 for %s, got %v", deviceID, specs)
+	}
+}
+
+// TestDeviceIDFormatStrictMismatch 测试严格模式下按配置的ID格式拒绝不匹配的设备ID
+func TestDeviceIDFormatStrictMismatch(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{DeviceIDFormat: DeviceIDFormatUUID, StrictDeviceIDs: true})
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	if msg := status.Convert(err).Message(); !strings.Contains(msg, DeviceIDFormatUUID) {
+		t.Errorf("Expected error to name the uuid format, got %q", msg)
+	}
+}
+
+// TestValidateDeviceIDFormat 测试设备ID格式校验
+func TestValidateDeviceIDFormat(t *testing.T) {
+	for _, format := range []string{"", "index", "uuid", "gpu-%d", "node0-dev%d"} {
+		if err := ValidateDeviceIDFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", format, err)
+		}
+	}
+	for _, format := range []string{"gpu", "gpu-%s", "gpu-%d-%d", "gpu-%d%%"} {
+		if err := ValidateDeviceIDFormat(format); err == nil {
+			t.Errorf("Expected %q to be rejected", format)
+		}
+	}
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 1, t.TempDir(), Options{DeviceIDFormat: "gpu"})
+	if err := plugin.Validate(); err == nil {
+		t.Error("Expected Validate to reject an invalid device ID format")
+	}
+}
@@ -0,0 +1,86 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// ParseMounts 解析"host:container[:ro],..."格式的挂载列表
+func ParseMounts(s string) ([]*v1beta1.Mount, error) {
+	mounts := []*v1beta1.Mount{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid mount %q: expected host:container[:ro]", item)
+		}
+
+		mount := &v1beta1.Mount{HostPath: parts[0], ContainerPath: parts[1]}
+		if len(parts) == 3 {
+			switch parts[2] {
+			case "ro":
+				mount.ReadOnly = true
+			case "rw":
+			default:
+				return nil, fmt.Errorf("invalid mount %q: mode must be ro or rw", item)
+			}
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+// deviceIndex 返回设备（分区返回所属设备）的序号，如ppu-3返回3
+func (p *PPUDevicePlugin) deviceIndex(deviceID string) (int, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	index, ok := p.indexes[p.parentDeviceLocked(deviceID)]
+	return index, ok
+}
+
+// deviceSpec 构建设备的设备规格，配置了路径模板时宿主机和容器内使用模板生成的路径（分区使用所属设备的序号），
+// 否则容器内路径为/dev/<id>并映射到/dev/null
+func (p *PPUDevicePlugin) deviceSpec(deviceID string) *v1beta1.DeviceSpec {
+	if p.opts.DevicePathTemplate != "" {
+		if index, ok := p.deviceIndex(deviceID); ok {
+			path := fmt.Sprintf(p.opts.DevicePathTemplate, index)
+			return &v1beta1.DeviceSpec{
+				ContainerPath: path,
+				HostPath:      path,
+				Permissions:   "rw",
+			}
+		}
+	}
+
+	return &v1beta1.DeviceSpec{
+		ContainerPath: "/dev/" + deviceID,
+		HostPath:      "/dev/null", // 模拟设备，使用/dev/null
+		Permissions:   "rw",
+	}
+}
+
+// cdiDevices 为分配的设备生成CDI设备名称，vendor/class取自资源名称，如alibabacloud.com/ppu=ppu-0
+func (p *PPUDevicePlugin) cdiDevices(deviceIDs []string) []*v1beta1.CDIDevice {
+	devices := make([]*v1beta1.CDIDevice, 0, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		devices = append(devices, &v1beta1.CDIDevice{Name: p.resourceName + "=" + deviceID})
+	}
+	return devices
+}
+
+// allocationMounts 返回添加到每个容器分配响应的挂载
+func (p *PPUDevicePlugin) allocationMounts() []*v1beta1.Mount {
+	mounts := make([]*v1beta1.Mount, 0, len(p.opts.Mounts))
+	for _, mount := range p.opts.Mounts {
+		m := *mount
+		mounts = append(mounts, &m)
+	}
+	return append(mounts, p.memoryMounts()...)
+}
@@ -0,0 +1,113 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestAllocateDevicePathTemplate 测试设备规格使用路径模板生成的路径
+func TestAllocateDevicePathTemplate(t *testing.T) {
+	plugin := newTestPlugin(t, 12, Options{DevicePathTemplate: "/dev/ppu%d"})
+
+	response := allocate(t, plugin, "ppu-1", "ppu-10")
+
+	expected := []*v1beta1.DeviceSpec{
+		{ContainerPath: "/dev/ppu1", HostPath: "/dev/ppu1", Permissions: "rw"},
+		{ContainerPath: "/dev/ppu10", HostPath: "/dev/ppu10", Permissions: "rw"},
+	}
+	if devices := response.ContainerResponses[0].Devices; !reflect.DeepEqual(devices, expected) {
+		t.Errorf("Expected device specs %v, got %v", expected, devices)
+	}
+}
+
+// TestAllocateDefaultDevicePath 测试未配置路径模板时设备映射到/dev/null
+func TestAllocateDefaultDevicePath(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	response := allocate(t, plugin, "ppu-0")
+
+	expected := []*v1beta1.DeviceSpec{{ContainerPath: "/dev/ppu-0", HostPath: "/dev/null", Permissions: "rw"}}
+	if devices := response.ContainerResponses[0].Devices; !reflect.DeepEqual(devices, expected) {
+		t.Errorf("Expected device specs %v, got %v", expected, devices)
+	}
+}
+
+// TestAllocateMounts 测试配置的挂载出现在每个容器分配响应中
+func TestAllocateMounts(t *testing.T) {
+	mounts, err := ParseMounts("/host/lib:/usr/lib/ppu:ro, /host/bin:/usr/bin/ppu")
+	if err != nil {
+		t.Fatalf("ParseMounts failed: %v", err)
+	}
+	plugin := newTestPlugin(t, 2, Options{Mounts: mounts})
+
+	response := allocate(t, plugin, "ppu-0")
+
+	expected := []*v1beta1.Mount{
+		{HostPath: "/host/lib", ContainerPath: "/usr/lib/ppu", ReadOnly: true},
+		{HostPath: "/host/bin", ContainerPath: "/usr/bin/ppu"},
+	}
+	if got := response.ContainerResponses[0].Mounts; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected mounts %v, got %v", expected, got)
+	}
+
+	for _, input := range []string{"/host", ":/container", "/a:/b:rx"} {
+		if _, err := ParseMounts(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+// TestAllocateMemoryMounts 测试配置共享内存和大页后分配响应包含对应挂载和大小注解
+func TestAllocateMemoryMounts(t *testing.T) {
+	hugepages, err := ParseHugepages("2Mi, 1Gi")
+	if err != nil {
+		t.Fatalf("ParseHugepages failed: %v", err)
+	}
+	plugin := newTestPlugin(t, 2, Options{ShmSize: "64Mi", Hugepages: hugepages})
+
+	response := allocate(t, plugin, "ppu-0")
+
+	expected := []*v1beta1.Mount{
+		{HostPath: "/dev/shm", ContainerPath: "/dev/shm"},
+		{HostPath: "/dev/hugepages-2Mi", ContainerPath: "/dev/hugepages-2Mi"},
+		{HostPath: "/dev/hugepages-1Gi", ContainerPath: "/dev/hugepages-1Gi"},
+	}
+	containerResponse := response.ContainerResponses[0]
+	if !reflect.DeepEqual(containerResponse.Mounts, expected) {
+		t.Errorf("Expected mounts %v, got %v", expected, containerResponse.Mounts)
+	}
+	if got := containerResponse.Annotations["ppu.alibabacloud.com/shm-size"]; got != "64Mi" {
+		t.Errorf("Expected shm-size annotation 64Mi, got %q", got)
+	}
+	if got := containerResponse.Annotations["ppu.alibabacloud.com/hugepages"]; got != "2Mi,1Gi" {
+		t.Errorf("Expected hugepages annotation 2Mi,1Gi, got %q", got)
+	}
+
+	if err := ValidateShmSize("64MB"); err == nil {
+		t.Error("Expected an error for shm size 64MB")
+	}
+	if _, err := ParseHugepages("4Ki"); err == nil {
+		t.Error("Expected an error for hugepage size 4Ki")
+	}
+}
+
+// TestAllocateCDIDevices 测试启用CDI时返回与分配设备对应的CDI名称
+func TestAllocateCDIDevices(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{EnableCDI: true})
+
+	response := allocate(t, plugin, "ppu-0", "ppu-3")
+	container := response.ContainerResponses[0]
+
+	expected := []*v1beta1.CDIDevice{{Name: "test.com/ppu=ppu-0"}, {Name: "test.com/ppu=ppu-3"}}
+	if !reflect.DeepEqual(container.CDIDevices, expected) {
+		t.Errorf("Expected CDI devices %v, got %v", expected, container.CDIDevices)
+	}
+	if len(container.Devices) != 0 {
+		t.Errorf("Expected no legacy device specs with CDI enabled, got %v", container.Devices)
+	}
+	if !plugin.Capabilities().CDI {
+		t.Error("Expected capabilities to report CDI enabled")
+	}
+}
@@ -0,0 +1,48 @@
+package deviceplugin
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Drain 停止向kubelet上报设备：通过ListAndWatch推送空的设备列表，然后等待DrainGrace，
+// 使kubelet将资源容量置零并平滑迁移Pod，ctx取消时提前返回
+func (p *PPUDevicePlugin) Drain(ctx context.Context) {
+	p.beginDrain()
+	waitDrainGrace(ctx, p.log, p.opts.DrainGrace)
+}
+
+// beginDrain 标记插件进入排空状态并通知ListAndWatch上报空列表
+func (p *PPUDevicePlugin) beginDrain() {
+	if p.draining.Swap(true) {
+		return
+	}
+
+	p.log.Infof("Draining device plugin for %s: advertising no devices", p.resourceName)
+	p.ready.Store(false)
+	p.notifyListAndWatch()
+}
+
+// Draining 返回插件是否处于排空状态
+func (p *PPUDevicePlugin) Draining() bool {
+	return p.draining.Load()
+}
+
+// waitDrainGrace 等待排空宽限期结束或ctx取消，通过logger记录等待过程
+func waitDrainGrace(ctx context.Context, logger *log.Entry, grace time.Duration) {
+	if grace <= 0 {
+		return
+	}
+
+	logger.Infof("Waiting %s for the kubelet to observe the drained devices", grace)
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		logger.Warnf("Drain grace interrupted: %v", ctx.Err())
+	}
+}
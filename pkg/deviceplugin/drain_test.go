@@ -0,0 +1,54 @@
+package deviceplugin
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestDrainAdvertisesEmptyList 测试排空时ListAndWatch推送空的设备列表，并等待宽限期后返回
+func TestDrainAdvertisesEmptyList(t *testing.T) {
+	grace := 100 * time.Millisecond
+	plugin := newTestPlugin(t, 4, Options{DrainGrace: grace})
+	plugin.ready.Store(true)
+	stream := runListAndWatch(t, plugin)
+
+	if got := len(stream.next(t).Devices); got != 4 {
+		t.Fatalf("Expected 4 devices before drain, got %d", got)
+	}
+
+	done := make(chan time.Duration, 1)
+	go func() {
+		start := time.Now()
+		plugin.Drain(context.Background())
+		done <- time.Since(start)
+	}()
+
+	if devices := stream.next(t).Devices; len(devices) != 0 {
+		t.Errorf("Expected an empty device list during drain, got %d devices", len(devices))
+	}
+	if elapsed := <-done; elapsed < grace {
+		t.Errorf("Expected Drain to wait the %s grace, returned after %s", grace, elapsed)
+	}
+	if plugin.Ready() || !plugin.Draining() {
+		t.Error("Expected a drained plugin to report not ready")
+	}
+}
+
+// TestManagerDrainWaitsOnce 测试Manager同时排空所有插件，只等待一次宽限期
+func TestManagerDrainWaitsOnce(t *testing.T) {
+	grace := 100 * time.Millisecond
+	first := newTestPlugin(t, 1, Options{DrainGrace: grace})
+	second := newTestPlugin(t, 1, Options{DrainGrace: grace})
+
+	start := time.Now()
+	NewManager(first, second).Drain(context.Background())
+	elapsed := time.Since(start)
+
+	if !first.Draining() || !second.Draining() {
+		t.Error("Expected every plugin to be draining")
+	}
+	if elapsed < grace || elapsed >= 2*grace {
+		t.Errorf("Expected a single %s grace, took %s", grace, elapsed)
+	}
+}
@@ -0,0 +1,78 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// DefaultEnvCountKey 默认的分配设备数量环境变量名
+	DefaultEnvCountKey = "PPU_DEVICE_COUNT"
+	// DefaultEnvDevicesKey 默认的分配设备列表环境变量名
+	DefaultEnvDevicesKey = "PPU_ALLOCATED_DEVICES"
+	// EnvContainerIndexKey 容器在Allocate请求中的序号环境变量名
+	EnvContainerIndexKey = "PPU_CONTAINER_INDEX"
+	// EnvTotalInPodKey Allocate请求中全部容器分配的设备总数环境变量名
+	EnvTotalInPodKey = "PPU_TOTAL_IN_POD"
+)
+
+// ParseEnvs 解析"key=val,key2=val2"格式的环境变量列表
+func ParseEnvs(s string) (map[string]string, error) {
+	envs := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid env %q: expected key=value", item)
+		}
+		envs[key] = value
+	}
+	return envs, nil
+}
+
+// allocationEnvs 构建容器分配响应的环境变量，静态变量不会覆盖设备相关变量
+func (p *PPUDevicePlugin) allocationEnvs(deviceIDs []string) map[string]string {
+	envs := make(map[string]string, len(p.opts.ExtraEnvs)+2)
+	for key, value := range p.opts.ExtraEnvs {
+		envs[key] = value
+	}
+
+	countKey := p.opts.EnvCountKey
+	if countKey == "" {
+		countKey = DefaultEnvCountKey
+	}
+	devicesKey := p.opts.EnvDevicesKey
+	if devicesKey == "" {
+		devicesKey = DefaultEnvDevicesKey
+	}
+
+	for key, value := range p.attributeEnvs(deviceIDs) {
+		envs[key] = value
+	}
+	envs[countKey] = fmt.Sprintf("%d", len(deviceIDs))
+	envs[devicesKey] = strings.Join(deviceIDs, ",")
+	return envs
+}
+
+// setPodEnvs 为请求中的每个容器响应写入容器序号和全部容器的设备总数，缓存命中的响应同样按本次请求更新
+func setPodEnvs(responses []*v1beta1.ContainerAllocateResponse, containerDevices [][]string) {
+	total := 0
+	for _, devices := range containerDevices {
+		total += len(devices)
+	}
+
+	for i, response := range responses {
+		if response.Envs == nil {
+			response.Envs = make(map[string]string, 2)
+		}
+		response.Envs[EnvContainerIndexKey] = fmt.Sprintf("%d", i)
+		response.Envs[EnvTotalInPodKey] = fmt.Sprintf("%d", total)
+	}
+}
@@ -0,0 +1,102 @@
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestAllocateCustomEnvKeys 测试自定义的环境变量名和静态环境变量出现在分配响应中
+func TestAllocateCustomEnvKeys(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{
+		EnvCountKey:   "NVIDIA_DEVICE_COUNT",
+		EnvDevicesKey: "NVIDIA_VISIBLE_DEVICES",
+		ExtraEnvs:     map[string]string{"PPU_DRIVER": "mock", "NVIDIA_VISIBLE_DEVICES": "ignored"},
+	})
+
+	response := allocate(t, plugin, "ppu-0", "ppu-1")
+
+	expected := map[string]string{
+		"NVIDIA_DEVICE_COUNT":    "2",
+		"NVIDIA_VISIBLE_DEVICES": "ppu-0,ppu-1",
+		"PPU_DRIVER":             "mock",
+		"PPU_CONTAINER_INDEX":    "0",
+		"PPU_TOTAL_IN_POD":       "2",
+	}
+	if envs := response.ContainerResponses[0].Envs; !reflect.DeepEqual(envs, expected) {
+		t.Errorf("Expected envs %v, got %v", expected, envs)
+	}
+}
+
+// TestAllocateAttributeEnvs 测试分配响应中包含每个分配设备的型号和显存环境变量
+func TestAllocateAttributeEnvs(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{
+		DeviceModel:     "PPU-X1",
+		DeviceMemoryMB:  16384,
+		DeviceOverrides: map[string]DeviceOverride{"ppu-2": {Model: "PPU-X2", MemoryMB: 32768}},
+	})
+
+	response := allocate(t, plugin, "ppu-0", "ppu-2")
+
+	expected := map[string]string{
+		"PPU_DEVICE_COUNT":      "2",
+		"PPU_ALLOCATED_DEVICES": "ppu-0,ppu-2",
+		"PPU_0_MODEL":           "PPU-X1",
+		"PPU_0_MEMORY_MB":       "16384",
+		"PPU_2_MODEL":           "PPU-X2",
+		"PPU_2_MEMORY_MB":       "32768",
+		"PPU_CONTAINER_INDEX":   "0",
+		"PPU_TOTAL_IN_POD":      "2",
+	}
+	if envs := response.ContainerResponses[0].Envs; !reflect.DeepEqual(envs, expected) {
+		t.Errorf("Expected envs %v, got %v", expected, envs)
+	}
+}
+
+// TestAllocatePodEnvs 测试多容器请求中每个容器获得不同的序号和相同的设备总数
+func TestAllocatePodEnvs(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{})
+
+	response, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-0"}},
+			{DevicesIDs: []string{"ppu-1", "ppu-2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	for i, expectedDevices := range []string{"ppu-0", "ppu-1,ppu-2"} {
+		envs := response.ContainerResponses[i].Envs
+		if got := envs[EnvContainerIndexKey]; got != strconv.Itoa(i) {
+			t.Errorf("Container %d: expected %s=%d, got %q", i, EnvContainerIndexKey, i, got)
+		}
+		if got := envs[EnvTotalInPodKey]; got != "3" {
+			t.Errorf("Container %d: expected %s=3, got %q", i, EnvTotalInPodKey, got)
+		}
+		if got := envs[DefaultEnvDevicesKey]; got != expectedDevices {
+			t.Errorf("Container %d: expected only its own devices %s, got %q", i, expectedDevices, got)
+		}
+	}
+}
+
+// TestParseEnvs 测试解析静态环境变量列表
+func TestParseEnvs(t *testing.T) {
+	envs, err := ParseEnvs("A=1, B=x=y,,C=")
+	if err != nil {
+		t.Fatalf("ParseEnvs failed: %v", err)
+	}
+	if expected := map[string]string{"A": "1", "B": "x=y", "C": ""}; !reflect.DeepEqual(envs, expected) {
+		t.Errorf("Expected %v, got %v", expected, envs)
+	}
+
+	for _, input := range []string{"A", "=1"} {
+		if _, err := ParseEnvs(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
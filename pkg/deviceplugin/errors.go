@@ -0,0 +1,15 @@
+package deviceplugin
+
+import "errors"
+
+// 启动过程各阶段的错误类型，调用方可以通过errors.Is区分失败原因
+var (
+	// ErrDeviceInit 模拟设备初始化失败
+	ErrDeviceInit = errors.New("device initialization failed")
+	// ErrSocketListen 插件gRPC服务无法在socket上监听
+	ErrSocketListen = errors.New("failed to listen on plugin socket")
+	// ErrRegistration 向kubelet注册失败（包括重试耗尽和被取消）
+	ErrRegistration = errors.New("kubelet registration failed")
+	// ErrKubeletSocketNotFound kubelet注册socket不存在
+	ErrKubeletSocketNotFound = errors.New("kubelet socket not found")
+)
@@ -0,0 +1,114 @@
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// eventBufferSize 每个订阅者的事件缓冲大小，缓冲满时丢弃新事件
+const eventBufferSize = 64
+
+// DeviceEvent 设备健康状态变化事件
+type DeviceEvent struct {
+	ID   string    `json:"id"`
+	Old  string    `json:"old"`
+	New  string    `json:"new"`
+	Time time.Time `json:"time"`
+}
+
+// eventBus 设备事件的发布订阅，每个订阅者拥有独立的缓冲通道，慢速订阅者不会阻塞健康检查
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan DeviceEvent]struct{}
+	log         *log.Entry
+}
+
+// newEventBus 创建事件总线，丢弃事件时通过logger记录
+func newEventBus(logger *log.Entry) *eventBus {
+	return &eventBus{subscribers: make(map[chan DeviceEvent]struct{}), log: logger}
+}
+
+// subscribe 订阅设备事件，返回事件通道和取消订阅的函数
+func (b *eventBus) subscribe() (<-chan DeviceEvent, func()) {
+	ch := make(chan DeviceEvent, eventBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// publish 向所有订阅者发送事件，订阅者缓冲已满时丢弃该事件
+func (b *eventBus) publish(event DeviceEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			b.log.Debugf("Event subscriber buffer full, dropping event for device %s", event.ID)
+		}
+	}
+}
+
+// SubscribeEvents 订阅设备健康状态变化事件，调用返回的函数取消订阅
+func (p *PPUDevicePlugin) SubscribeEvents() (<-chan DeviceEvent, func()) {
+	return p.events.subscribe()
+}
+
+// handleEvents 以Server-Sent Events格式持续推送设备事件，直到客户端断开或插件停止
+func (p *PPUDevicePlugin) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := p.SubscribeEvents()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				p.log.Warnf("Failed to encode device event: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: health\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+
+		case <-p.stop:
+			return
+		}
+	}
+}
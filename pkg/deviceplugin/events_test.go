@@ -0,0 +1,89 @@
+package deviceplugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestHealthTransitionEvents 测试健康检查中的设备状态变化发布到事件总线，重复的状态不产生事件
+func TestHealthTransitionEvents(t *testing.T) {
+	source := &fakeHealthSource{events: make(chan DeviceHealthEvent)}
+	plugin := newTestPlugin(t, 2, Options{HealthSource: source})
+
+	events, cancel := plugin.SubscribeEvents()
+	defer cancel()
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	source.events <- DeviceHealthEvent{ID: "ppu-1", Health: v1beta1.Healthy}
+	source.events <- DeviceHealthEvent{ID: "ppu-1", Health: v1beta1.Unhealthy}
+
+	select {
+	case event := <-events:
+		if event.ID != "ppu-1" || event.Old != v1beta1.Healthy || event.New != v1beta1.Unhealthy {
+			t.Errorf("Expected ppu-1 Healthy -> Unhealthy, got %+v", event)
+		}
+		if event.Time.IsZero() {
+			t.Error("Expected event time to be set")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Transition event was not published")
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("Expected no further events, got %+v", event)
+	default:
+	}
+}
+
+// TestEventsEndpoint 测试/events以Server-Sent Events格式推送设备状态变化
+func TestEventsEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{EnableAdminAPI: true})
+	server := httptest.NewServer(plugin.adminHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", got)
+	}
+
+	plugin.applyHealthEvent(DeviceHealthEvent{ID: "ppu-0", Health: v1beta1.Unhealthy})
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event DeviceEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("Failed to decode event %q: %v", data, err)
+		}
+		if event.ID != "ppu-0" || event.Old != v1beta1.Healthy || event.New != v1beta1.Unhealthy {
+			t.Errorf("Expected ppu-0 Healthy -> Unhealthy, got %+v", event)
+		}
+		return
+	}
+	t.Fatalf("Stream ended without an event: %v", scanner.Err())
+}
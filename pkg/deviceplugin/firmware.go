@@ -0,0 +1,100 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseFirmwareVersions 解析固件版本配置，格式为 "v1:ppu-0,ppu-1;v2:ppu-2,ppu-3"，返回deviceID到版本的映射
+func ParseFirmwareVersions(spec string) (map[string]string, error) {
+	versions := make(map[string]string)
+	if strings.TrimSpace(spec) == "" {
+		return versions, nil
+	}
+
+	for _, part := range strings.Split(spec, ";") {
+		version, devices, found := strings.Cut(part, ":")
+		version = strings.TrimSpace(version)
+		if !found || version == "" {
+			return nil, fmt.Errorf("invalid firmware group %q, expected version:device,...", part)
+		}
+
+		groups, err := ParseDeviceGroups(devices)
+		if err != nil || len(groups) == 0 {
+			return nil, fmt.Errorf("invalid devices for firmware %s: %q", version, devices)
+		}
+		for _, deviceID := range groups[0] {
+			if existing, exists := versions[deviceID]; exists {
+				return nil, fmt.Errorf("device %s assigned to both firmware %s and %s", deviceID, existing, version)
+			}
+			versions[deviceID] = version
+		}
+	}
+
+	return versions, nil
+}
+
+// isFirmwareHomogeneous 判断一组设备是否使用相同的固件版本
+func isFirmwareHomogeneous(deviceIDs []string, versionOf map[string]string) bool {
+	for _, deviceID := range deviceIDs {
+		if versionOf[deviceID] != versionOf[deviceIDs[0]] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFirmwareCandidates 将可用设备限制为同一固件版本，以满足size个设备的分配
+// 必须包含的设备决定目标版本；否则按可用列表顺序选择第一个设备数量足够的版本。
+// 无法满足时返回原始列表和false，由调用方回退并告警。
+func sameFirmwareCandidates(available, mustInclude []string, size int, versionOf map[string]string) ([]string, bool) {
+	if len(mustInclude) > 0 && !isFirmwareHomogeneous(mustInclude, versionOf) {
+		return available, false
+	}
+
+	byVersion := make(map[string][]string)
+	order := []string{}
+	for _, deviceID := range available {
+		version := versionOf[deviceID]
+		if _, exists := byVersion[version]; !exists {
+			order = append(order, version)
+		}
+		byVersion[version] = append(byVersion[version], deviceID)
+	}
+
+	// 统计某版本候选设备加上必须包含的设备后是否足够
+	enough := func(version string) bool {
+		count := len(byVersion[version])
+		for _, deviceID := range mustInclude {
+			if !contains(byVersion[version], deviceID) {
+				count++
+			}
+		}
+		return count >= size
+	}
+
+	if len(mustInclude) > 0 {
+		version := versionOf[mustInclude[0]]
+		if enough(version) {
+			return byVersion[version], true
+		}
+		return available, false
+	}
+
+	for _, version := range order {
+		if enough(version) {
+			return byVersion[version], true
+		}
+	}
+	return available, false
+}
+
+// contains 判断切片中是否包含指定字符串
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestSameFirmwareCandidates 测试同版本和混合版本可用设备下的候选集合
+func TestSameFirmwareCandidates(t *testing.T) {
+	versionOf := map[string]string{
+		"ppu-0": "v1", "ppu-1": "v1",
+		"ppu-2": "v2", "ppu-3": "v2", "ppu-4": "v2",
+	}
+
+	tests := []struct {
+		name        string
+		available   []string
+		mustInclude []string
+		size        int
+		expected    []string
+		homogeneous bool
+	}{
+		{
+			name:        "SameVersionAvailable",
+			available:   []string{"ppu-0", "ppu-2", "ppu-3", "ppu-4"},
+			size:        3,
+			expected:    []string{"ppu-2", "ppu-3", "ppu-4"},
+			homogeneous: true,
+		},
+		{
+			name:        "MustIncludePicksVersion",
+			available:   []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"},
+			mustInclude: []string{"ppu-3"},
+			size:        2,
+			expected:    []string{"ppu-2", "ppu-3"},
+			homogeneous: true,
+		},
+		{
+			name:        "MixedVersionsFallback",
+			available:   []string{"ppu-0", "ppu-2", "ppu-3"},
+			size:        3,
+			expected:    []string{"ppu-0", "ppu-2", "ppu-3"},
+			homogeneous: false,
+		},
+		{
+			name:        "MixedMustIncludeFallback",
+			available:   []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"},
+			mustInclude: []string{"ppu-0", "ppu-2"},
+			size:        2,
+			expected:    []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"},
+			homogeneous: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, ok := sameFirmwareCandidates(tt.available, tt.mustInclude, tt.size, versionOf)
+			if ok != tt.homogeneous {
+				t.Errorf("Expected homogeneous=%v, got %v", tt.homogeneous, ok)
+			}
+			if !reflect.DeepEqual(candidates, tt.expected) {
+				t.Errorf("Expected candidates %v, got %v", tt.expected, candidates)
+			}
+		})
+	}
+}
+
+// TestPreferredAllocationFirmwareHomogeneous 测试首选分配保持设备固件版本一致
+func TestPreferredAllocationFirmwareHomogeneous(t *testing.T) {
+	versions, err := ParseFirmwareVersions("v1:ppu-0,ppu-1;v2:ppu-2,ppu-3")
+	if err != nil {
+		t.Fatalf("ParseFirmwareVersions failed: %v", err)
+	}
+	plugin := newTestPlugin(t, 4, Options{FirmwareVersions: versions, FirmwareHomogeneous: true})
+
+	response, err := plugin.GetPreferredAllocation(context.Background(), &v1beta1.PreferredAllocationRequest{
+		ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs: []string{"ppu-0", "ppu-2", "ppu-3"},
+			AllocationSize:     2,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("GetPreferredAllocation failed: %v", err)
+	}
+
+	selected := response.ContainerResponses[0].DeviceIDs
+	if !reflect.DeepEqual(selected, []string{"ppu-2", "ppu-3"}) {
+		t.Errorf("Expected same-firmware devices [ppu-2 ppu-3], got %v", selected)
+	}
+	if !isFirmwareHomogeneous(selected, versions) {
+		t.Errorf("Expected selection %v to share a firmware version", selected)
+	}
+}
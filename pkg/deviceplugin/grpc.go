@@ -2,183 +2,561 @@ package deviceplugin
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"sort"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
 // GetDevicePluginOptions 返回设备插件选项
 func (p *PPUDevicePlugin) GetDevicePluginOptions(ctx context.Context, empty *v1beta1.Empty) (*v1beta1.DevicePluginOptions, error) {
-	log.Debug("GetDevicePluginOptions called")
+	p.log.Debug("GetDevicePluginOptions called")
 
 	options := &v1beta1.DevicePluginOptions{
-		PreStartRequired: false,
+		PreStartRequired:                p.opts.PreStartRequired,
+		GetPreferredAllocationAvailable: !p.opts.DisablePreferredAllocation,
 	}
 
-	log.Debugf("Returning device plugin options: %+v", options)
+	p.log.Debugf("Returning device plugin options: %+v", options)
 	return options, nil
 }
 
 // ListAndWatch 返回设备列表，并监听设备状态变化
 func (p *PPUDevicePlugin) ListAndWatch(empty *v1beta1.Empty, stream v1beta1.DevicePlugin_ListAndWatchServer) error {
-	log.Info("ListAndWatch called - starting device monitoring")
+	p.log.Info("ListAndWatch called - starting device monitoring")
 
-	// 发送初始设备列表
-	devices := make([]*v1beta1.Device, 0, len(p.devices))
-	for _, device := range p.devices {
-		devices = append(devices, device)
+	if err := p.throttleListAndWatch(stream.Context()); err != nil {
+		return err
 	}
+	if !p.delayInitialList(stream.Context()) {
+		return nil
+	}
+
+	// 发送初始设备列表
+	devices := p.deviceList()
 
 	response := &v1beta1.ListAndWatchResponse{
 		Devices: devices,
 	}
 
-	log.Debugf("Sending initial device list with %d devices", len(devices))
-	if err := stream.Send(response); err != nil {
-		log.Errorf("Failed to send initial device list: %v", err)
+	p.log.Debugf("Sending initial device list with %d devices", len(devices))
+	if err := p.send(stream, response); err != nil {
+		p.log.Errorf("Failed to send initial device list: %v", err)
 		return err
 	}
 
-	log.Info("Initial device list sent successfully")
+	p.log.Info("Initial device list sent successfully")
+
+	p.trackListAndWatchStream(1)
+	defer p.trackListAndWatchStream(-1)
 
-	// 持续监听设备状态变化和健康检查
+	// 持续监听设备状态变化和健康检查，防抖窗口内的多次变化合并为一次上报
+	var flush <-chan time.Time
 	for {
 		select {
 		case device := <-p.health:
-			log.Debugf("Device health update received: %s, health: %s", device.ID, device.Health)
+			p.log.Debugf("Device health update received: %s, health: %s", device.ID, device.Health)
 
 			// 更新设备状态
-			if existingDevice, exists := p.devices[device.ID]; exists {
-				existingDevice.Health = device.Health
-				log.Debugf("Updated device %s health to: %s", device.ID, device.Health)
+			if p.setDeviceHealth(device.ID, device.Health) {
+				p.log.Debugf("Updated device %s health to: %s", device.ID, device.Health)
 			}
 
-			// 发送更新后的设备列表
-			updatedDevices := make([]*v1beta1.Device, 0, len(p.devices))
-			for _, dev := range p.devices {
-				updatedDevices = append(updatedDevices, dev)
+			// 设备变为不健康后不再被容器占用，释放其分配记录
+			if device.Health != v1beta1.Healthy {
+				p.releaseDevices([]string{device.ID})
+			}
+
+			if p.opts.WatchDebounce <= 0 {
+				if err := p.sendDeviceList(stream); err != nil {
+					return err
+				}
+			} else if flush == nil {
+				flush = time.After(p.opts.WatchDebounce)
 			}
 
-			updateResponse := &v1beta1.ListAndWatchResponse{
-				Devices: updatedDevices,
+		case <-p.listChanged:
+			p.log.Debug("Device list changed")
+			if p.opts.WatchDebounce <= 0 {
+				if err := p.sendDeviceList(stream); err != nil {
+					return err
+				}
+			} else if flush == nil {
+				flush = time.After(p.opts.WatchDebounce)
 			}
 
-			if err := stream.Send(updateResponse); err != nil {
-				log.Errorf("Failed to send device list update: %v", err)
+		case <-flush:
+			flush = nil
+			if err := p.sendDeviceList(stream); err != nil {
 				return err
 			}
 
-			log.Debugf("Device list update sent successfully")
+		case <-stream.Context().Done():
+			// 客户端断开后立即退出，避免继续消费健康事件并向失效的流发送
+			p.log.Infof("ListAndWatch client disconnected: %v", stream.Context().Err())
+			return nil
 
 		case <-p.stop:
-			log.Info("ListAndWatch stopped")
+			p.log.Info("ListAndWatch stopped")
 			return nil
 		}
 	}
 }
 
+// trackListAndWatchStream 调整活跃的ListAndWatch流计数
+func (p *PPUDevicePlugin) trackListAndWatchStream(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.listAndWatchStreams += delta
+}
+
+// activeListAndWatchStreams 返回当前活跃的ListAndWatch流数量
+func (p *PPUDevicePlugin) activeListAndWatchStreams() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.listAndWatchStreams
+}
+
+// sendDeviceList 向ListAndWatch流发送当前的设备列表
+func (p *PPUDevicePlugin) sendDeviceList(stream v1beta1.DevicePlugin_ListAndWatchServer) error {
+	response := &v1beta1.ListAndWatchResponse{
+		Devices: p.deviceList(),
+	}
+
+	if err := p.send(stream, response); err != nil {
+		p.log.Errorf("Failed to send device list update: %v", err)
+		return err
+	}
+
+	p.log.Debugf("Device list update sent successfully")
+	return nil
+}
+
+// send 向ListAndWatch流发送响应，失败时按WatchSendRetries指数退避重试，插件停止或客户端断开时放弃重试
+func (p *PPUDevicePlugin) send(stream v1beta1.DevicePlugin_ListAndWatchServer, response *v1beta1.ListAndWatchResponse) error {
+	backoff := p.opts.WatchSendBackoff
+	if backoff <= 0 {
+		backoff = defaultWatchSendBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		err := stream.Send(response)
+		if err == nil || attempt >= p.opts.WatchSendRetries {
+			return err
+		}
+
+		p.log.Warnf("ListAndWatch send attempt %d failed: %v, retrying in %s", attempt+1, err, backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-stream.Context().Done():
+			timer.Stop()
+			return err
+		case <-p.stop:
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// deviceList 生成需要上报给kubelet的设备列表
+func (p *PPUDevicePlugin) deviceList() []*v1beta1.Device {
+	// 排空期间不上报任何设备
+	if p.Draining() {
+		p.log.Debug("Device plugin is draining, advertising an empty device list")
+		return []*v1beta1.Device{}
+	}
+	if p.Maintenance() {
+		p.log.Debug("Device plugin is in maintenance mode, advertising an empty device list")
+		return []*v1beta1.Device{}
+	}
+
+	if devices, ok := p.sourcedDeviceList(); ok {
+		return devices
+	}
+
+	p.mu.RLock()
+	devices := make([]*v1beta1.Device, 0, len(p.devices))
+	healthy := 0
+	for deviceID, device := range p.devices {
+		// 预留的设备不上报给kubelet
+		if p.reserved[deviceID] {
+			continue
+		}
+		// 复制设备，避免发送过程中与健康检查并发修改
+		devices = append(devices, copyDevice(device))
+		if device.Health == v1beta1.Healthy {
+			healthy++
+		}
+	}
+	p.mu.RUnlock()
+
+	// 所有设备均不健康时，按配置上报空列表
+	if p.opts.EmptyOnAllUnhealthy && len(devices) > 0 && healthy == 0 {
+		p.log.Warnf("All %d devices are unhealthy, advertising an empty device list", len(devices))
+		return []*v1beta1.Device{}
+	}
+
+	// 默认按设备ID稳定排序，开启后每次上报随机打乱顺序
+	sortDevices(devices)
+	if p.opts.ShuffleDevices {
+		p.shuffleDevices(devices)
+	}
+
+	return devices
+}
+
+// copyDevice 复制设备信息
+func copyDevice(device *v1beta1.Device) *v1beta1.Device {
+	return &v1beta1.Device{
+		ID:       device.ID,
+		Health:   device.Health,
+		Topology: device.Topology,
+	}
+}
+
+// deviceReserved 返回设备是否已被预留
+func (p *PPUDevicePlugin) deviceReserved(deviceID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.reserved[deviceID]
+}
+
+// deviceHealth 返回设备的健康状态以及设备是否存在
+func (p *PPUDevicePlugin) deviceHealth(deviceID string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	device, exists := p.devices[deviceID]
+	if !exists {
+		return "", false
+	}
+	return device.Health, true
+}
+
+// setDeviceHealth 更新设备的健康状态，设备不存在时返回false
+func (p *PPUDevicePlugin) setDeviceHealth(deviceID, health string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	device, exists := p.devices[deviceID]
+	if !exists {
+		return false
+	}
+	device.Health = health
+	p.updateDeviceGaugesLocked()
+	return true
+}
+
+// allocationOwner 返回设备当前的分配对象，包括本次请求中已选中的设备
+func (p *PPUDevicePlugin) allocationOwner(deviceID string, claims map[string]string) (string, bool) {
+	if owner, exists := claims[deviceID]; exists {
+		return owner, true
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	owner, exists := p.allocated[deviceID]
+	return owner, exists
+}
+
+// claimConflictLocked 提交分配前在写锁内重新检查本次请求选中的设备，避免并发的Allocate重复分配同一设备，调用方需持有p.mu写锁
+func (p *PPUDevicePlugin) claimConflictLocked(claims map[string]string) error {
+	ids := make([]string, 0, len(claims))
+	for deviceID := range claims {
+		ids = append(ids, deviceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+
+	for _, deviceID := range ids {
+		if holder, taken := p.allocated[deviceID]; taken && holder != claims[deviceID] {
+			return status.Errorf(codes.ResourceExhausted, "device %s is already allocated to %s", deviceID, holder)
+		}
+		if _, exists := p.devices[deviceID]; !exists {
+			return status.Errorf(codes.FailedPrecondition, "device %s no longer exists", deviceID)
+		}
+		if p.reserved[deviceID] {
+			return status.Errorf(codes.FailedPrecondition, "device %s is reserved", deviceID)
+		}
+		if p.permanentlyDead[deviceID] {
+			return status.Errorf(codes.FailedPrecondition, "device %s is permanently dead", deviceID)
+		}
+	}
+	return nil
+}
+
+// releaseDevices 释放设备的分配记录，使其可以再次分配
+func (p *PPUDevicePlugin) releaseDevices(ids []string) {
+	p.mu.Lock()
+	released := 0
+	for _, deviceID := range ids {
+		if owner, exists := p.allocated[deviceID]; exists {
+			delete(p.allocated, deviceID)
+			delete(p.busyUntil, deviceID)
+			p.setUtilizationLocked(deviceID, idleUtilization)
+			p.log.Infof("Released device %s from %s", deviceID, owner)
+			released++
+		}
+	}
+	if released > 0 {
+		p.updateDeviceGaugesLocked()
+	}
+	p.invalidateAllocationCacheLocked(ids...)
+	p.mu.Unlock()
+
+	if released > 0 {
+		p.saveState()
+	}
+}
+
 // Allocate 分配设备给Pod
-func (p *PPUDevicePlugin) Allocate(ctx context.Context, request *v1beta1.AllocateRequest) (*v1beta1.AllocateResponse, error) {
-	log.Infof("Allocate called with %d container requests", len(request.ContainerRequests))
+func (p *PPUDevicePlugin) Allocate(ctx context.Context, request *v1beta1.AllocateRequest) (response *v1beta1.AllocateResponse, err error) {
+	p.log.Infof("Allocate called with %d container requests", len(request.ContainerRequests))
+
+	allocationID := p.nextAllocationID()
+	p.mu.RLock()
+	generation := p.resetGeneration
+	p.mu.RUnlock()
+	start := time.Now()
+	defer func() {
+		p.recordAllocation(allocationID, request, err)
+		outcome := allocationOutcome(err)
+		p.metrics.allocateRequests.WithLabelValues(outcome).Inc()
+		p.metrics.allocateDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	if p.simulateAllocateFailure() {
+		p.log.Warn("Rejecting allocation: simulated allocation failure")
+		return nil, status.Error(codes.Internal, "simulated allocation failure")
+	}
+
+	if p.warmingUp() {
+		p.log.Warn("Rejecting allocation: device plugin is still warming up")
+		return nil, status.Error(codes.Unavailable, "device plugin is warming up")
+	}
+
+	if limit := p.opts.MaxDevicesPerContainer; limit > 0 {
+		for i, containerRequest := range request.ContainerRequests {
+			if len(containerRequest.DevicesIDs) > limit {
+				p.log.Warnf("Container request %d asks for %d devices, exceeding the limit of %d",
+					i, len(containerRequest.DevicesIDs), limit)
+				return nil, status.Errorf(codes.InvalidArgument,
+					"container request %d asks for %d devices, at most %d are allowed per container",
+					i, len(containerRequest.DevicesIDs), limit)
+			}
+		}
+	}
+
+	if err := p.injectAllocationDelay(ctx, request); err != nil {
+		p.log.Warnf("Allocate aborted during injected delay: %v", err)
+		return nil, status.FromContextError(err).Err()
+	}
 
 	responses := make([]*v1beta1.ContainerAllocateResponse, 0, len(request.ContainerRequests))
+	containerDevices := make([][]string, 0, len(request.ContainerRequests))
+	cached := make([]bool, 0, len(request.ContainerRequests))
+	claims := make(map[string]string)
 
 	for i, containerRequest := range request.ContainerRequests {
-		log.Debugf("Processing container request %d with %d device IDs: %v",
+		owner := allocationOwner(allocationID, i)
+		p.log.Debugf("Processing container request %d with %d device IDs: %v",
 			i, len(containerRequest.DevicesIDs), containerRequest.DevicesIDs)
 
+		// kubelet重试相同的请求时直接返回缓存的结果，保证响应一致
+		if response, devices, cachedOwner, ok := p.cachedAllocationFor(allocationSignature(containerRequest.DevicesIDs)); ok {
+			p.log.Infof("Container request %d matches a cached allocation of %v", i, devices)
+			// 缓存的设备同样计入本次请求的选中设备，避免同一请求中的其他容器再次分到这些设备
+			for _, deviceID := range devices {
+				if holder, taken := claims[deviceID]; taken {
+					p.log.Warnf("Device %s is already allocated to %s", deviceID, holder)
+					return nil, status.Errorf(codes.ResourceExhausted, "device %s is already allocated to %s", deviceID, holder)
+				}
+				claims[deviceID] = cachedOwner
+			}
+			responses = append(responses, response)
+			containerDevices = append(containerDevices, devices)
+			cached = append(cached, true)
+			continue
+		}
+
 		// 验证请求的设备是否存在且健康
 		allocatedDevices := []string{}
 		for _, deviceID := range containerRequest.DevicesIDs {
-			if device, exists := p.devices[deviceID]; exists {
-				if device.Health == v1beta1.Healthy {
+			if holder, taken := p.allocationOwner(deviceID, claims); taken {
+				p.log.Warnf("Device %s is already allocated to %s", deviceID, holder)
+				return nil, status.Errorf(codes.ResourceExhausted, "device %s is already allocated to %s", deviceID, holder)
+			}
+
+			if p.deviceReserved(deviceID) {
+				p.log.Warnf("Device %s is reserved", deviceID)
+				return nil, status.Errorf(codes.FailedPrecondition, "device %s is reserved", deviceID)
+			}
+
+			if p.deviceDead(deviceID) {
+				p.log.Warnf("Device %s is permanently dead", deviceID)
+				return nil, status.Errorf(codes.FailedPrecondition, "device %s is permanently dead", deviceID)
+			}
+
+			if health, exists := p.deviceHealth(deviceID); exists {
+				if health == v1beta1.Healthy {
 					allocatedDevices = append(allocatedDevices, deviceID)
-					log.Debugf("Device %s allocated successfully", deviceID)
+					claims[deviceID] = owner
+					p.log.Debugf("Device %s allocated successfully", deviceID)
 				} else {
-					log.Warnf("Device %s is not healthy, health status: %s", deviceID, device.Health)
+					p.log.Warnf("Device %s is not healthy, health status: %s", deviceID, health)
+				}
+			} else if matches, expected := p.matchesDeviceIDFormat(deviceID); !matches {
+				// 多个插件同时运行时容易把其他插件的设备ID路由到这里
+				p.log.Warnf("Requested device %s does not match expected %s, it may belong to another device plugin",
+					deviceID, expected)
+				if p.opts.StrictDeviceIDs {
+					return nil, status.Errorf(codes.InvalidArgument,
+						"device ID %s does not match expected %s for resource %s", deviceID, expected, p.resourceName)
 				}
 			} else {
-				log.Warnf("Requested device %s not found", deviceID)
+				p.log.Warnf("Requested device %s not found", deviceID)
 			}
 		}
 
 		// 构建容器分配响应
 		containerResponse := &v1beta1.ContainerAllocateResponse{
-			Envs: map[string]string{
-				"PPU_DEVICE_COUNT":      fmt.Sprintf("%d", len(allocatedDevices)),
-				"PPU_ALLOCATED_DEVICES": strings.Join(allocatedDevices, ","),
-			},
-			Mounts:  []*v1beta1.Mount{},
-			Devices: []*v1beta1.DeviceSpec{},
-			Annotations: map[string]string{
-				"ppu.alibabacloud.com/allocated-devices": strings.Join(allocatedDevices, ","),
-			},
+			Envs:        p.allocationEnvs(allocatedDevices),
+			Mounts:      p.allocationMounts(),
+			Devices:     []*v1beta1.DeviceSpec{},
+			Annotations: p.allocationAnnotations(allocatedDevices),
 		}
 
-		// 为每个分配的设备添加设备规格（模拟设备文件）
-		for _, deviceID := range allocatedDevices {
-			deviceSpec := &v1beta1.DeviceSpec{
-				ContainerPath: "/dev/" + deviceID,
-				HostPath:      "/dev/null", // 模拟设备，使用/dev/null
-				Permissions:   "rw",
+		if p.opts.EnableCDI {
+			// 启用CDI时由运行时根据CDI名称注入设备，不再返回传统的设备规格
+			containerResponse.CDIDevices = p.cdiDevices(allocatedDevices)
+		} else {
+			// 为每个分配的设备添加设备规格（模拟设备文件）
+			for _, deviceID := range allocatedDevices {
+				deviceSpec := p.deviceSpec(deviceID)
+				containerResponse.Devices = append(containerResponse.Devices, deviceSpec)
+				p.log.Debugf("Added device spec for %s: %s -> %s", deviceID, deviceSpec.HostPath, deviceSpec.ContainerPath)
 			}
-			containerResponse.Devices = append(containerResponse.Devices, deviceSpec)
-			log.Debugf("Added device spec for %s: %s -> %s", deviceID, deviceSpec.HostPath, deviceSpec.ContainerPath)
+		}
+
+		// 运行注册的分配钩子，任一钩子失败则中止本次分配
+		if err := p.runAllocationHooks(allocatedDevices, containerResponse); err != nil {
+			p.log.Errorf("Allocation hook failed for container request %d: %v", i, err)
+			return nil, status.Errorf(codes.Internal, "allocation hook failed: %v", err)
 		}
 
 		responses = append(responses, containerResponse)
-		log.Infof("Container request %d processed: allocated %d devices", i, len(allocatedDevices))
+		containerDevices = append(containerDevices, allocatedDevices)
+		cached = append(cached, false)
+		p.log.Infof("Container request %d processed: allocated %d devices", i, len(allocatedDevices))
 	}
 
+	setPodEnvs(responses, containerDevices)
 	allocateResponse := &v1beta1.AllocateResponse{
 		ContainerResponses: responses,
 	}
 
-	log.Infof("Allocate completed: returning %d container responses", len(responses))
+	// 调用方已放弃时不再记录分配结果，避免设备被无人使用的分配占用
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	// 所有容器请求处理成功后再记录分配结果，检查与提交在同一写锁内完成
+	p.mu.Lock()
+	if p.resetGeneration != generation {
+		p.mu.Unlock()
+		p.log.Warn("Rejecting allocation at commit: devices were reset during the request")
+		return nil, status.Error(codes.Aborted, "devices were reset during allocation")
+	}
+	if err := p.claimConflictLocked(claims); err != nil {
+		p.mu.Unlock()
+		p.log.Warnf("Rejecting allocation at commit: %v", err)
+		return nil, err
+	}
+	now := time.Now()
+	committed := 0
+	for deviceID, owner := range claims {
+		// 命中缓存且仍由原分配对象持有的设备无需重复提交
+		if holder, taken := p.allocated[deviceID]; taken && holder == owner {
+			continue
+		}
+		committed++
+		p.allocated[deviceID] = owner
+		p.markBusyLocked(deviceID, now)
+		p.setUtilizationLocked(deviceID, allocatedUtilization)
+		if p.opts.PerDeviceMetrics {
+			p.metrics.deviceAllocations.WithLabelValues(deviceID).Inc()
+		}
+	}
+	p.allocationsServed++
+	p.metrics.allocatedDevices.Add(float64(committed))
+	p.updateDeviceGaugesLocked()
+	p.mu.Unlock()
+	p.saveState()
+	p.writeAuditLog(allocationID, containerDevices)
+
+	for i, containerResponse := range responses {
+		if cached[i] {
+			continue
+		}
+		containerResponse.Annotations[p.annotationKey("utilization")] = p.utilizationAnnotation(containerDevices[i])
+		p.cacheAllocation(request.ContainerRequests[i].DevicesIDs, containerDevices[i], allocationOwner(allocationID, i), containerResponse)
+	}
+
+	p.log.Infof("Allocate completed: returning %d container responses", len(responses))
 	return allocateResponse, nil
 }
 
 // GetPreferredAllocation 返回首选的设备分配
 func (p *PPUDevicePlugin) GetPreferredAllocation(ctx context.Context, request *v1beta1.PreferredAllocationRequest) (*v1beta1.PreferredAllocationResponse, error) {
-	log.Debugf("GetPreferredAllocation called with %d container requests", len(request.ContainerRequests))
+	p.log.Debugf("GetPreferredAllocation called with %d container requests", len(request.ContainerRequests))
+
+	if p.opts.DisablePreferredAllocation {
+		return nil, status.Error(codes.Unimplemented, "preferred allocation is disabled")
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
 
 	responses := make([]*v1beta1.ContainerPreferredAllocationResponse, 0, len(request.ContainerRequests))
+	claimed := make(map[string]bool)
 
 	for i, containerRequest := range request.ContainerRequests {
-		log.Debugf("Processing preferred allocation for container %d, requested: %d, available: %d",
-			i, containerRequest.MustIncludeDeviceIDs, len(containerRequest.AvailableDeviceIDs))
-
-		// 简单的分配策略：优先选择前面的设备
-		selectedDeviceIDs := []string{}
-
-		// 首先包含必须包含的设备
-		for _, deviceID := range containerRequest.MustIncludeDeviceIDs {
-			selectedDeviceIDs = append(selectedDeviceIDs, deviceID)
-		}
-
-		// 然后从可用设备中选择剩余需要的设备
-		needed := int(containerRequest.AllocationSize) - len(selectedDeviceIDs)
-		for _, deviceID := range containerRequest.AvailableDeviceIDs {
-			if needed <= 0 {
-				break
-			}
-
-			// 检查设备是否已经在必须包含的列表中
-			alreadySelected := false
-			for _, selectedID := range selectedDeviceIDs {
-				if selectedID == deviceID {
-					alreadySelected = true
-					break
-				}
+		p.log.Debugf("Processing preferred allocation for container %d, requested: %d, available: %d",
+			i, containerRequest.AllocationSize, len(containerRequest.AvailableDeviceIDs))
+
+		size := int(containerRequest.AllocationSize)
+		// 只在Allocate会授予的设备中选择，使首选结果与实际分配一致
+		available := p.allocatableDevices(containerRequest.AvailableDeviceIDs, claimed)
+
+		// 优先将容器的设备限制在同一固件版本内
+		if p.opts.FirmwareHomogeneous {
+			candidates, ok := sameFirmwareCandidates(available, containerRequest.MustIncludeDeviceIDs, size, p.opts.FirmwareVersions)
+			if ok {
+				available = candidates
+			} else {
+				p.log.Warnf("Container %d: cannot satisfy %d devices on a single firmware version, falling back to mixed versions", i, size)
 			}
+		}
 
-			if !alreadySelected {
-				selectedDeviceIDs = append(selectedDeviceIDs, deviceID)
-				needed--
-			}
+		var selectedDeviceIDs []string
+		if node, ok := p.preferredNUMANode(ctx); ok {
+			selectedDeviceIDs = p.selectOnNUMANode(available, containerRequest.MustIncludeDeviceIDs, size, node)
+		} else {
+			selectedDeviceIDs = p.selectPreferred(available, containerRequest.MustIncludeDeviceIDs, size)
+		}
+		for _, deviceID := range selectedDeviceIDs {
+			claimed[deviceID] = true
 		}
 
 		containerResponse := &v1beta1.ContainerPreferredAllocationResponse{
@@ -186,73 +564,54 @@ func (p *PPUDevicePlugin) GetPreferredAllocation(ctx context.Context, request *v
 		}
 
 		responses = append(responses, containerResponse)
-		log.Debugf("Preferred allocation for container %d: selected %v", i, selectedDeviceIDs)
+		p.log.Debugf("Preferred allocation for container %d: selected %v", i, selectedDeviceIDs)
+	}
+
+	if err := contextError(ctx); err != nil {
+		return nil, err
 	}
 
 	preferredResponse := &v1beta1.PreferredAllocationResponse{
 		ContainerResponses: responses,
 	}
 
-	log.Debugf("GetPreferredAllocation completed: returning %d container responses", len(responses))
+	p.log.Debugf("GetPreferredAllocation completed: returning %d container responses", len(responses))
 	return preferredResponse, nil
 }
 
-// PreStart 在容器启动前执行的钩子函数
+// PreStart 在容器启动前执行的钩子函数，启用PreStartRequired时校验请求的设备均存在
 func (p *PPUDevicePlugin) PreStart(ctx context.Context, request *v1beta1.PreStartContainerRequest) (*v1beta1.PreStartContainerResponse, error) {
-	log.Debugf("PreStart called for %d devices", len(request.DevicesIDs))
+	p.log.Debugf("PreStart called for %d devices", len(request.DevicesIDs))
+
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	if !p.opts.PreStartRequired {
+		// 未要求PreStart时kubelet不会调用该钩子，直接返回
+		return &v1beta1.PreStartContainerResponse{}, nil
+	}
 
-	// 对于模拟设备，我们不需要执行实际的预启动操作
-	// 只是记录日志以便调试
 	for _, deviceID := range request.DevicesIDs {
-		log.Debugf("PreStart processing device: %s", deviceID)
+		p.log.Debugf("PreStart processing device: %s", deviceID)
+		if _, exists := p.deviceHealth(deviceID); !exists {
+			p.log.Warnf("PreStart requested unknown device %s", deviceID)
+			return nil, status.Errorf(codes.NotFound, "device %s not found", deviceID)
+		}
+	}
+
+	if err := contextError(ctx); err != nil {
+		return nil, err
 	}
 
 	response := &v1beta1.PreStartContainerResponse{}
-	log.Debug("PreStart completed successfully")
+	p.log.Debug("PreStart completed successfully")
 
 	return response, nil
 }
 
+// PreStartContainer 实现DevicePlugin接口的PreStartContainer，逻辑与PreStart相同
 func (p *PPUDevicePlugin) PreStartContainer(ctx context.Context, request *v1beta1.PreStartContainerRequest) (*v1beta1.PreStartContainerResponse, error) {
-	log.Debugf("PreStartContainer called for %d devices", len(request.DevicesIDs))
-	return &v1beta1.PreStartContainerResponse{}, nil
-}
-
-// startHealthCheck 启动设备健康检查
-func (p *PPUDevicePlugin) startHealthCheck() {
-	log.Info("Starting device health check routine")
-
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				log.Debug("Performing periodic health check")
-
-				// 模拟设备健康检查
-				for deviceID, device := range p.devices {
-					// 在真实环境中，这里会检查实际的设备状态
-					// 对于模拟设备，我们假设所有设备都是健康的
-					if device.Health != v1beta1.Healthy {
-						log.Debugf("Device %s health check: changing from %s to Healthy", deviceID, device.Health)
-						device.Health = v1beta1.Healthy
-
-						// 发送健康状态更新
-						select {
-						case p.health <- device:
-							log.Debugf("Health update sent for device %s", deviceID)
-						default:
-							log.Debugf("Health channel full, skipping update for device %s", deviceID)
-						}
-					}
-				}
-
-			case <-p.stop:
-				log.Info("Health check routine stopped")
-				return
-			}
-		}
-	}()
+	p.log.Debugf("PreStartContainer called for %d devices", len(request.DevicesIDs))
+	return p.PreStart(ctx, request)
 }
@@ -0,0 +1,604 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// fakeListAndWatchStream 记录ListAndWatch发送的响应
+type fakeListAndWatchStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *v1beta1.ListAndWatchResponse
+}
+
+// newFakeListAndWatchStream 创建模拟的ListAndWatch流
+func newFakeListAndWatchStream() *fakeListAndWatchStream {
+	return &fakeListAndWatchStream{
+		ctx:       context.Background(),
+		responses: make(chan *v1beta1.ListAndWatchResponse, 100),
+	}
+}
+
+func (s *fakeListAndWatchStream) Send(response *v1beta1.ListAndWatchResponse) error {
+	s.responses <- response
+	return nil
+}
+
+func (s *fakeListAndWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+// next 等待下一个发送的响应
+func (s *fakeListAndWatchStream) next(t *testing.T) *v1beta1.ListAndWatchResponse {
+	t.Helper()
+
+	select {
+	case response := <-s.responses:
+		return response
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for ListAndWatch response")
+		return nil
+	}
+}
+
+// newTestPlugin 创建已初始化设备的测试插件
+func newTestPlugin(t *testing.T, deviceCount int, opts Options) *PPUDevicePlugin {
+	t.Helper()
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", deviceCount, t.TempDir(), opts)
+	if err := plugin.initDevices(); err != nil {
+		t.Fatalf("initDevices failed: %v", err)
+	}
+	return plugin
+}
+
+// runListAndWatch 在后台运行ListAndWatch，测试结束时停止
+func runListAndWatch(t *testing.T, plugin *PPUDevicePlugin) *fakeListAndWatchStream {
+	t.Helper()
+
+	stream := newFakeListAndWatchStream()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		plugin.ListAndWatch(&v1beta1.Empty{}, stream)
+	}()
+	t.Cleanup(func() {
+		close(plugin.stop)
+		<-done
+	})
+	return stream
+}
+
+// TestListAndWatchClientDisconnect 测试流上下文取消后ListAndWatch无错误返回，重复连接不会累积消费者
+func TestListAndWatchClientDisconnect(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := newFakeListAndWatchStream()
+		stream.ctx = ctx
+
+		result := make(chan error, 1)
+		go func() {
+			result <- plugin.ListAndWatch(&v1beta1.Empty{}, stream)
+		}()
+		stream.next(t)
+		cancel()
+
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Fatalf("Expected ListAndWatch to return nil after disconnect, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("ListAndWatch did not return after the stream context was canceled")
+		}
+
+		if active := plugin.activeListAndWatchStreams(); active != 0 {
+			t.Fatalf("Expected no active ListAndWatch streams after disconnect %d, got %d", i+1, active)
+		}
+	}
+}
+
+// TestListAndWatchAllUnhealthy 测试所有设备不健康时上报的设备列表
+func TestListAndWatchAllUnhealthy(t *testing.T) {
+	tests := []struct {
+		name                string
+		emptyOnAllUnhealthy bool
+		expectedDevices     int
+	}{
+		{name: "FullList", emptyOnAllUnhealthy: false, expectedDevices: 4},
+		{name: "EmptyList", emptyOnAllUnhealthy: true, expectedDevices: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, 4, Options{EmptyOnAllUnhealthy: tt.emptyOnAllUnhealthy})
+			for _, device := range plugin.devices {
+				device.Health = v1beta1.Unhealthy
+			}
+
+			stream := runListAndWatch(t, plugin)
+			response := stream.next(t)
+
+			if len(response.Devices) != tt.expectedDevices {
+				t.Fatalf("Expected %d advertised devices, got %d", tt.expectedDevices, len(response.Devices))
+			}
+			for _, device := range response.Devices {
+				if device.Health != v1beta1.Unhealthy {
+					t.Errorf("Expected device %s to be Unhealthy, got %s", device.ID, device.Health)
+				}
+			}
+		})
+	}
+}
+
+// TestAllocateDuringWarmup 测试预热期内分配被拒绝，预热结束后成功
+func TestAllocateDuringWarmup(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{Warmup: 100 * time.Millisecond})
+	plugin.ready.Store(true)
+	plugin.beginWarmup()
+
+	if plugin.Ready() {
+		t.Error("Expected plugin not to be ready during warmup")
+	}
+
+	request := &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	}
+
+	_, err := plugin.Allocate(context.Background(), request)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected Unavailable during warmup, got %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if !plugin.Ready() {
+		t.Error("Expected plugin to be ready after warmup")
+	}
+	if _, err := plugin.Allocate(context.Background(), request); err != nil {
+		t.Fatalf("Expected Allocate to succeed after warmup, got %v", err)
+	}
+}
+
+// TestAllocateDelayPerDevice 测试分配延迟随请求设备数量线性增长
+func TestAllocateDelayPerDevice(t *testing.T) {
+	perDevice := 20 * time.Millisecond
+	plugin := newTestPlugin(t, 4, Options{AllocationDelayPerDevice: perDevice})
+
+	request := &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}},
+		},
+	}
+
+	start := time.Now()
+	if _, err := plugin.Allocate(context.Background(), request); err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < 4*perDevice || elapsed > 4*perDevice+100*time.Millisecond {
+		t.Errorf("Expected Allocate to take roughly %s, took %s", 4*perDevice, elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start = time.Now()
+	_, err := plugin.Allocate(ctx, request)
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("Expected Canceled error, got %v", err)
+	}
+	if time.Since(start) >= 4*perDevice {
+		t.Errorf("Expected canceled Allocate to return promptly, took %s", time.Since(start))
+	}
+}
+
+// TestAllocateLatencyJitter 测试分配延迟在基础延迟与抖动上限之间随机分布
+func TestAllocateLatencyJitter(t *testing.T) {
+	latency := 200 * time.Millisecond
+	jitter := 50 * time.Millisecond
+	plugin := newTestPlugin(t, 2, Options{AllocateLatency: latency, AllocateLatencyJitter: jitter, ChaosSeed: 42})
+
+	request := &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	}
+
+	delays := map[time.Duration]bool{}
+	for i := 0; i < 20; i++ {
+		delay := plugin.allocationDelay(request)
+		if delay < latency || delay >= latency+jitter {
+			t.Fatalf("Expected delay in [%s, %s), got %s", latency, latency+jitter, delay)
+		}
+		delays[delay] = true
+	}
+	if len(delays) < 2 {
+		t.Errorf("Expected jitter to randomize the delay, got %v", delays)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := plugin.Allocate(ctx, request); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= latency {
+		t.Errorf("Expected Allocate to return once the context expired, took %s", elapsed)
+	}
+}
+
+// TestHandlersRejectCanceledContext 测试各gRPC处理函数收到已取消的上下文时返回Canceled且不分配设备
+func TestHandlersRejectCanceledContext(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{PreStartRequired: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handlers := map[string]func() error{
+		"Allocate": func() error {
+			_, err := plugin.Allocate(ctx, &v1beta1.AllocateRequest{
+				ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+			})
+			return err
+		},
+		"GetPreferredAllocation": func() error {
+			_, err := plugin.GetPreferredAllocation(ctx, &v1beta1.PreferredAllocationRequest{
+				ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{
+					{AvailableDeviceIDs: []string{"ppu-0", "ppu-1"}, AllocationSize: 1},
+				},
+			})
+			return err
+		},
+		"PreStart": func() error {
+			_, err := plugin.PreStart(ctx, &v1beta1.PreStartContainerRequest{DevicesIDs: []string{"ppu-0"}})
+			return err
+		},
+		"PreStartContainer": func() error {
+			_, err := plugin.PreStartContainer(ctx, &v1beta1.PreStartContainerRequest{DevicesIDs: []string{"ppu-0"}})
+			return err
+		},
+	}
+
+	for name, call := range handlers {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); status.Code(err) != codes.Canceled {
+				t.Errorf("Expected Canceled error, got %v", err)
+			}
+		})
+	}
+
+	if _, allocated := plugin.allocationOwner("ppu-0", nil); allocated {
+		t.Error("Expected a canceled Allocate not to allocate devices")
+	}
+}
+
+// TestAllocateRejectsDoubleAllocation 测试已分配的设备不能再次分配，释放后可以重新分配
+func TestAllocateRejectsDoubleAllocation(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	allocate(t, plugin, "ppu-0")
+
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0", "ppu-1"}}},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted for double allocation, got %v", err)
+	}
+	if _, exists := plugin.allocated["ppu-1"]; exists {
+		t.Error("Expected rejected request not to claim ppu-1")
+	}
+
+	_, err = plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-1"}},
+			{DevicesIDs: []string{"ppu-1"}},
+		},
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted for a device requested by two containers, got %v", err)
+	}
+
+	plugin.releaseDevices([]string{"ppu-0"})
+	if _, exists := plugin.allocated["ppu-0"]; exists {
+		t.Fatal("Expected ppu-0 to be released")
+	}
+	allocate(t, plugin, "ppu-0")
+}
+
+// TestAllocateConcurrentSameDevice 测试并发请求同一设备时只有一个Allocate成功，其余返回ResourceExhausted
+func TestAllocateConcurrentSameDevice(t *testing.T) {
+	plugin := newTestPlugin(t, 1, Options{})
+	// 拉长检查与提交之间的窗口，使并发请求都能通过初步检查
+	plugin.RegisterAllocationHook(func(deviceID string, resp *v1beta1.ContainerAllocateResponse) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	const workers = 8
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+				ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch status.Code(err) {
+		case codes.OK:
+			succeeded++
+		case codes.ResourceExhausted:
+		default:
+			t.Errorf("Expected ResourceExhausted for losing requests, got %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one Allocate to succeed, got %d", succeeded)
+	}
+}
+
+// TestListAndWatchReleasesUnhealthyDevice 测试设备变为不健康时释放其分配
+func TestListAndWatchReleasesUnhealthyDevice(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+	allocate(t, plugin, "ppu-0")
+
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	plugin.health <- &v1beta1.Device{ID: "ppu-0", Health: v1beta1.Unhealthy}
+	stream.next(t)
+
+	plugin.mu.RLock()
+	_, exists := plugin.allocated["ppu-0"]
+	plugin.mu.RUnlock()
+	if exists {
+		t.Error("Expected unhealthy device to be released")
+	}
+}
+
+// TestConcurrentDeviceAccess 在-race下并发运行Allocate、健康检查和ListAndWatch
+func TestConcurrentDeviceAccess(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{HealthCheckInterval: time.Millisecond})
+	stream := runListAndWatch(t, plugin)
+	plugin.StartHealthCheck()
+
+	// 持续消费上报，避免阻塞ListAndWatch
+	go func() {
+		for range stream.responses {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		deviceID := fmt.Sprintf("ppu-%d", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+					ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{deviceID}}},
+				})
+				plugin.releaseDevices([]string{deviceID})
+				plugin.setDeviceHealth(deviceID, v1beta1.Unhealthy)
+				plugin.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestAllocateFailureRate 测试失败率为1时每次Allocate都返回Internal错误，为0时从不失败
+func TestAllocateFailureRate(t *testing.T) {
+	request := func(deviceID string) *v1beta1.AllocateRequest {
+		return &v1beta1.AllocateRequest{
+			ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{deviceID}}},
+		}
+	}
+
+	failing := newTestPlugin(t, 4, Options{AllocateFailureRate: 1, RNGSeed: 7})
+	for i := 0; i < 4; i++ {
+		_, err := failing.Allocate(context.Background(), request(fmt.Sprintf("ppu-%d", i)))
+		if status.Code(err) != codes.Internal || status.Convert(err).Message() != "simulated allocation failure" {
+			t.Errorf("Allocate %d: expected simulated Internal failure, got %v", i, err)
+		}
+	}
+	if got := len(failing.allocated); got != 0 {
+		t.Errorf("Expected failed allocations to leave no devices allocated, got %d", got)
+	}
+
+	healthy := newTestPlugin(t, 4, Options{AllocateFailureRate: 0, RNGSeed: 7})
+	for i := 0; i < 4; i++ {
+		if _, err := healthy.Allocate(context.Background(), request(fmt.Sprintf("ppu-%d", i))); err != nil {
+			t.Errorf("Allocate %d: expected success with rate 0, got %v", i, err)
+		}
+	}
+}
+
+// TestMaxDevicesPerContainer 测试单个容器请求超过设备上限时返回InvalidArgument且不分配任何设备
+func TestMaxDevicesPerContainer(t *testing.T) {
+	plugin := newTestPlugin(t, 8, Options{MaxDevicesPerContainer: 4})
+
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{
+			{DevicesIDs: []string{"ppu-0"}},
+			{DevicesIDs: []string{"ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5"}},
+		},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for 5 devices with a max of 4, got %v", err)
+	}
+	if got := len(plugin.allocated); got != 0 {
+		t.Errorf("Expected no devices to be allocated, got %d", got)
+	}
+
+	allocate(t, plugin, "ppu-0", "ppu-1", "ppu-2", "ppu-3")
+}
+
+// TestAllocatePrefixMismatch 测试设备ID前缀不匹配时的处理
+func TestAllocatePrefixMismatch(t *testing.T) {
+	request := &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"gpu-0"}}},
+	}
+
+	lenient := newTestPlugin(t, 2, Options{})
+	response, err := lenient.Allocate(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected mismatched ID to be ignored by default, got %v", err)
+	}
+	if got := response.ContainerResponses[0].Envs["PPU_DEVICE_COUNT"]; got != "0" {
+		t.Errorf("Expected 0 allocated devices, got %s", got)
+	}
+
+	strict := newTestPlugin(t, 2, Options{StrictDeviceIDs: true})
+	_, err = strict.Allocate(context.Background(), request)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument in strict mode, got %v", err)
+	}
+	if msg := status.Convert(err).Message(); !strings.Contains(msg, "gpu-0") || !strings.Contains(msg, DeviceIDPrefix) {
+		t.Errorf("Expected error to name the received ID and expected prefix, got %q", msg)
+	}
+}
+
+// TestListAndWatchDebounce 测试防抖窗口内的多次健康变化合并为一次上报
+func TestListAndWatchDebounce(t *testing.T) {
+	plugin := newTestPlugin(t, 10, Options{WatchDebounce: 100 * time.Millisecond})
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	for i := 0; i < 10; i++ {
+		plugin.health <- &v1beta1.Device{ID: fmt.Sprintf("ppu-%d", i), Health: v1beta1.Unhealthy}
+	}
+
+	// 防抖计时器到期后即使没有新事件也要发送
+	response := stream.next(t)
+	for _, device := range response.Devices {
+		if device.Health != v1beta1.Unhealthy {
+			t.Errorf("Expected the consolidated update to mark %s Unhealthy, got %s", device.ID, device.Health)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if sends := 1 + len(stream.responses); sends >= 10 {
+		t.Errorf("Expected fewer than 10 updates for 10 rapid events, got %d", sends)
+	}
+}
+
+// flakyListAndWatchStream 前failures次发送失败，之后记录发送的响应
+type flakyListAndWatchStream struct {
+	*fakeListAndWatchStream
+	mu       sync.Mutex
+	failures int
+	attempts int
+}
+
+func (s *flakyListAndWatchStream) Send(response *v1beta1.ListAndWatchResponse) error {
+	s.mu.Lock()
+	s.attempts++
+	fail := s.attempts <= s.failures
+	s.mu.Unlock()
+
+	if fail {
+		return fmt.Errorf("transient send failure")
+	}
+	return s.fakeListAndWatchStream.Send(response)
+}
+
+// TestListAndWatchSendRetry 测试首次发送失败后重试成功，流不会被关闭；未配置重试时直接返回错误
+func TestListAndWatchSendRetry(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{WatchSendRetries: 2, WatchSendBackoff: time.Millisecond})
+	stream := &flakyListAndWatchStream{fakeListAndWatchStream: newFakeListAndWatchStream(), failures: 1}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- plugin.ListAndWatch(&v1beta1.Empty{}, stream)
+	}()
+
+	if response := stream.next(t); len(response.Devices) != 2 {
+		t.Errorf("Expected 2 devices after retry, got %d", len(response.Devices))
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("ListAndWatch returned after a transient failure: %v", err)
+	default:
+	}
+	close(plugin.stop)
+	if err := <-done; err != nil {
+		t.Errorf("Expected ListAndWatch to stop cleanly, got %v", err)
+	}
+
+	plugin = newTestPlugin(t, 2, Options{})
+	stream = &flakyListAndWatchStream{fakeListAndWatchStream: newFakeListAndWatchStream(), failures: 1}
+	if err := plugin.ListAndWatch(&v1beta1.Empty{}, stream); err == nil {
+		t.Error("Expected the send error without retries")
+	}
+}
+
+// TestListAndWatchSendRetryStops 测试插件停止时放弃等待重试
+func TestListAndWatchSendRetryStops(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{WatchSendRetries: 5, WatchSendBackoff: time.Hour})
+	stream := &flakyListAndWatchStream{fakeListAndWatchStream: newFakeListAndWatchStream(), failures: 10}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- plugin.ListAndWatch(&v1beta1.Empty{}, stream)
+	}()
+	close(plugin.stop)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected the send error after stopping")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListAndWatch kept retrying after the plugin stopped")
+	}
+}
+
+// TestListAndWatchInitialDelay 测试首次设备列表在配置的延迟之后发送，插件停止时提前返回
+func TestListAndWatchInitialDelay(t *testing.T) {
+	delay := 200 * time.Millisecond
+	plugin := newTestPlugin(t, 2, Options{ListAndWatchInitialDelay: delay})
+
+	start := time.Now()
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+	if elapsed := time.Since(start); elapsed < delay || elapsed > delay+time.Second {
+		t.Errorf("Expected the first send after about %s, got %s", delay, elapsed)
+	}
+
+	stopped := newTestPlugin(t, 2, Options{ListAndWatchInitialDelay: time.Hour})
+	done := make(chan error, 1)
+	go func() {
+		done <- stopped.ListAndWatch(&v1beta1.Empty{}, newFakeListAndWatchStream())
+	}()
+	close(stopped.stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean return on stop, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListAndWatch kept waiting for the initial delay after stop")
+	}
+}
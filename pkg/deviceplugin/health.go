@@ -0,0 +1,266 @@
+package deviceplugin
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// DeviceHealthEvent 设备健康状态事件
+type DeviceHealthEvent struct {
+	ID     string
+	Health string
+}
+
+// HealthSource 设备健康状态来源，Watch返回的通道在ctx取消后关闭
+type HealthSource interface {
+	Watch(ctx context.Context) <-chan DeviceHealthEvent
+}
+
+// mockHealthSource 基于定时器的模拟健康来源，周期性地将不健康的设备恢复为健康，并按配置的概率随机注入故障
+type mockHealthSource struct {
+	plugin   *PPUDevicePlugin
+	interval time.Duration
+}
+
+// Watch 每个周期（按HealthJitter随机错开）为所有不健康的设备发送恢复事件，并按各设备的故障概率注入故障
+func (s *mockHealthSource) Watch(ctx context.Context) <-chan DeviceHealthEvent {
+	out := make(chan DeviceHealthEvent)
+
+	go func() {
+		defer close(out)
+
+		timer := time.NewTimer(s.plugin.jitteredInterval(s.interval))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				timer.Reset(s.plugin.jitteredInterval(s.interval))
+				s.plugin.log.Debug("Performing periodic health check")
+
+				// 在真实环境中，这里会检查实际的设备状态
+				// 对于模拟设备，不健康的设备在下一周期恢复，健康的设备按配置的概率发生故障
+				s.plugin.driftTemperatures()
+				events := s.recoveryEvents()
+				for _, deviceID := range s.plugin.rollDeviceFailures() {
+					events = append(events, DeviceHealthEvent{ID: deviceID, Health: v1beta1.Unhealthy})
+				}
+				for _, event := range events {
+					select {
+					case out <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// recoveryEvents 为所有可自动恢复的不健康设备生成恢复事件
+func (s *mockHealthSource) recoveryEvents() []DeviceHealthEvent {
+	events := []DeviceHealthEvent{}
+	for _, deviceID := range s.plugin.recoverableDeviceIDs() {
+		events = append(events, DeviceHealthEvent{ID: deviceID, Health: v1beta1.Healthy})
+	}
+	return events
+}
+
+// jitteredInterval 在interval基础上按HealthJitter比例随机增减，使多个实例的健康检查错开
+func (p *PPUDevicePlugin) jitteredInterval(interval time.Duration) time.Duration {
+	if p.opts.HealthJitter <= 0 {
+		return interval
+	}
+
+	p.mu.Lock()
+	offset := (p.rng.Float64()*2 - 1) * p.opts.HealthJitter
+	p.mu.Unlock()
+
+	return time.Duration(float64(interval) * (1 + offset))
+}
+
+// healthCheckInterval 返回健康检查周期，未配置时使用默认值
+func (p *PPUDevicePlugin) healthCheckInterval() time.Duration {
+	if p.opts.HealthCheckInterval <= 0 {
+		return defaultHealthCheckInterval
+	}
+	return p.opts.HealthCheckInterval
+}
+
+// healthBufferSize 返回健康状态更新通道的容量，未配置时使用默认值
+func healthBufferSize(opts Options) int {
+	if opts.HealthBufferSize <= 0 {
+		return defaultHealthBufferSize
+	}
+	return opts.HealthBufferSize
+}
+
+// healthSource 返回配置的健康来源，未配置时使用健康检查命令或基于定时器的模拟来源
+func (p *PPUDevicePlugin) healthSource() HealthSource {
+	if p.opts.HealthSource != nil {
+		return p.opts.HealthSource
+	}
+	if p.opts.HealthCommand != "" {
+		return &commandHealthSource{
+			plugin:   p,
+			command:  p.opts.HealthCommand,
+			interval: p.healthCheckInterval(),
+			timeout:  p.healthCommandTimeout(),
+		}
+	}
+	return &mockHealthSource{plugin: p, interval: p.healthCheckInterval()}
+}
+
+// startHealthCheck 启动设备健康检查，消费健康来源的事件直到插件停止
+func (p *PPUDevicePlugin) startHealthCheck(ctx context.Context) {
+	p.log.Info("Starting device health check routine")
+
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	p.healthCtx = ctx
+	p.runHealthCheckLocked()
+}
+
+// runHealthCheckLocked 订阅健康来源并在后台处理事件，调用方需持有p.healthMu
+func (p *PPUDevicePlugin) runHealthCheckLocked() {
+	ctx, cancel := context.WithCancel(p.healthCtx)
+	p.healthCancel = cancel
+	events := p.healthSource().Watch(ctx)
+
+	go func() {
+		defer cancel()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					p.log.Info("Health source closed")
+					return
+				}
+				// 暂停后不再应用仍在途中的事件
+				if ctx.Err() != nil {
+					return
+				}
+				p.applyHealthEvent(event)
+
+			case <-p.stop:
+				p.log.Info("Health check routine stopped")
+				return
+
+			case <-ctx.Done():
+				p.log.Info("Health check routine canceled")
+				return
+			}
+		}
+	}()
+}
+
+// PauseHealthCheck 暂停健康检查，设备状态保持不变直到ResumeHealthCheck
+func (p *PPUDevicePlugin) PauseHealthCheck() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	if p.healthCancel == nil || p.healthPaused {
+		return
+	}
+
+	p.healthCancel()
+	p.healthPaused = true
+	p.log.Info("Health check paused")
+}
+
+// ResumeHealthCheck 恢复被暂停的健康检查
+func (p *PPUDevicePlugin) ResumeHealthCheck() {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	if !p.healthPaused {
+		return
+	}
+
+	p.healthPaused = false
+	p.runHealthCheckLocked()
+	p.log.Info("Health check resumed")
+}
+
+// recoverableDeviceIDs 返回当前不健康且允许自动恢复的设备ID
+func (p *PPUDevicePlugin) recoverableDeviceIDs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ids := []string{}
+	for deviceID, device := range p.devices {
+		if device.Health != v1beta1.Healthy && !p.stickyUnhealthy[deviceID] && !p.permanentlyDead[deviceID] {
+			ids = append(ids, deviceID)
+		}
+	}
+	return ids
+}
+
+// applyHealthEvent 更新设备健康状态，状态变化时通知ListAndWatch；永久损坏的设备始终保持不健康
+func (p *PPUDevicePlugin) applyHealthEvent(event DeviceHealthEvent) {
+	p.mu.Lock()
+	device, exists := p.devices[event.ID]
+	if !exists {
+		p.mu.Unlock()
+		p.log.Warnf("Health event for unknown device %s", event.ID)
+		return
+	}
+	if p.permanentlyDead[event.ID] && event.Health == v1beta1.Healthy {
+		p.mu.Unlock()
+		p.log.Debugf("Ignoring recovery of permanently dead device %s", event.ID)
+		return
+	}
+	if device.Health == event.Health {
+		p.mu.Unlock()
+		return
+	}
+
+	p.log.Debugf("Device %s health check: changing from %s to %s", event.ID, device.Health, event.Health)
+	transition := DeviceEvent{ID: event.ID, Old: device.Health, New: event.Health, Time: time.Now()}
+	device.Health = event.Health
+	p.invalidateAllocationCacheLocked(event.ID)
+	update := copyDevice(device)
+	p.updateDeviceGaugesLocked()
+	p.mu.Unlock()
+
+	p.events.publish(transition)
+
+	// 发送健康状态更新，发送时不持有锁以免阻塞ListAndWatch
+	p.sendHealthUpdate(update)
+}
+
+// sendHealthUpdate 将健康状态更新放入缓冲通道，通道已满时最多等待HealthSendTimeout，
+// 仍无法发送时丢弃更新，记录警告并增加丢弃计数
+func (p *PPUDevicePlugin) sendHealthUpdate(update *v1beta1.Device) {
+	select {
+	case p.health <- update:
+		p.log.Debugf("Health update sent for device %s", update.ID)
+		return
+	default:
+	}
+
+	if p.opts.HealthSendTimeout > 0 {
+		timer := time.NewTimer(p.opts.HealthSendTimeout)
+		defer timer.Stop()
+
+		select {
+		case p.health <- update:
+			p.log.Debugf("Health update sent for device %s", update.ID)
+			return
+		case <-timer.C:
+		case <-p.stop:
+		}
+	}
+
+	p.metrics.healthUpdatesDropped.Inc()
+	p.log.Warnf("Health channel full, dropped update for device %s (health %s)", update.ID, update.Health)
+}
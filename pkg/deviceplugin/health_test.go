@@ -0,0 +1,280 @@
+package deviceplugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestHealthCheckInterval 测试健康检查按配置的周期执行
+func TestHealthCheckInterval(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{HealthCheckInterval: 10 * time.Millisecond})
+	plugin.devices["ppu-1"].Health = v1beta1.Unhealthy
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	select {
+	case device := <-plugin.health:
+		if device.ID != "ppu-1" || device.Health != v1beta1.Healthy {
+			t.Errorf("Expected ppu-1 to recover to Healthy, got %s %s", device.ID, device.Health)
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("Health check did not fire within 50ms")
+	}
+}
+
+// fakeHealthSource 由测试直接驱动的健康来源
+type fakeHealthSource struct {
+	events chan DeviceHealthEvent
+}
+
+func (s *fakeHealthSource) Watch(ctx context.Context) <-chan DeviceHealthEvent {
+	return s.events
+}
+
+// TestCustomHealthSource 测试健康检查消费配置的健康来源
+func TestCustomHealthSource(t *testing.T) {
+	source := &fakeHealthSource{events: make(chan DeviceHealthEvent)}
+	plugin := newTestPlugin(t, 2, Options{HealthSource: source})
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	for _, health := range []string{v1beta1.Unhealthy, v1beta1.Healthy} {
+		go func(health string) {
+			source.events <- DeviceHealthEvent{ID: "ppu-0", Health: health}
+		}(health)
+
+		select {
+		case device := <-plugin.health:
+			if device.ID != "ppu-0" || device.Health != health {
+				t.Errorf("Expected ppu-0 to become %s, got %s %s", health, device.ID, device.Health)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Transition to %s was not propagated", health)
+		}
+
+		if got, _ := plugin.deviceHealth("ppu-0"); got != health {
+			t.Errorf("Expected stored health %s, got %s", health, got)
+		}
+	}
+}
+
+// TestInjectedUnhealthyDevicesStayUnhealthy 测试注入的不健康设备不会被健康检查恢复
+func TestInjectedUnhealthyDevicesStayUnhealthy(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{
+		HealthCheckInterval: 10 * time.Millisecond,
+		UnhealthyDevices:    []string{"ppu-1", "ppu-9"},
+	})
+	plugin.devices["ppu-2"].Health = v1beta1.Unhealthy
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if got, _ := plugin.deviceHealth("ppu-2"); got == v1beta1.Healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected ppu-2 to recover within 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if got, _ := plugin.deviceHealth("ppu-1"); got != v1beta1.Unhealthy {
+		t.Errorf("Expected injected device ppu-1 to stay Unhealthy, got %s", got)
+	}
+}
+
+// TestUnhealthyRatio 测试按比例随机注入不健康设备
+func TestUnhealthyRatio(t *testing.T) {
+	plugin := newTestPlugin(t, 8, Options{UnhealthyRatio: 0.25})
+
+	unhealthy := 0
+	for _, device := range plugin.deviceList() {
+		if device.Health == v1beta1.Unhealthy {
+			unhealthy++
+		}
+	}
+	if unhealthy != 2 {
+		t.Errorf("Expected 2 unhealthy devices, got %d", unhealthy)
+	}
+}
+
+// TestPauseHealthCheck 测试暂停期间不会自动恢复设备，恢复后继续检查
+func TestPauseHealthCheck(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{HealthCheckInterval: 10 * time.Millisecond})
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	plugin.PauseHealthCheck()
+	plugin.mu.Lock()
+	plugin.devices["ppu-1"].Health = v1beta1.Unhealthy
+	plugin.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	if got, _ := plugin.deviceHealth("ppu-1"); got != v1beta1.Unhealthy {
+		t.Fatalf("Expected ppu-1 to stay Unhealthy while paused, got %s", got)
+	}
+
+	plugin.ResumeHealthCheck()
+	select {
+	case device := <-plugin.health:
+		if device.ID != "ppu-1" || device.Health != v1beta1.Healthy {
+			t.Errorf("Expected ppu-1 to recover after resume, got %s %s", device.ID, device.Health)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Health check did not fire after resume")
+	}
+}
+
+// TestPerDeviceFailureProbability 测试固定种子下各设备的故障次数符合各自的故障概率
+func TestPerDeviceFailureProbability(t *testing.T) {
+	probabilities := map[string]float64{"ppu-0": 0.05, "ppu-1": 0.3, "ppu-2": 0.8}
+	overrides := make(map[string]DeviceOverride, len(probabilities))
+	for deviceID, probability := range probabilities {
+		overrides[deviceID] = DeviceOverride{FailureProbability: probability}
+	}
+	plugin := newTestPlugin(t, 4, Options{ChaosSeed: 42, DeviceOverrides: overrides})
+
+	const ticks = 2000
+	failures := make(map[string]int)
+	for i := 0; i < ticks; i++ {
+		for _, deviceID := range plugin.rollDeviceFailures() {
+			failures[deviceID]++
+		}
+	}
+
+	if failures["ppu-3"] != 0 {
+		t.Errorf("Expected ppu-3 without a failure probability never to fail, got %d", failures["ppu-3"])
+	}
+	for deviceID, probability := range probabilities {
+		rate := float64(failures[deviceID]) / ticks
+		if rate < probability-0.05 || rate > probability+0.05 {
+			t.Errorf("Expected %s failure rate near %.2f, got %.3f", deviceID, probability, rate)
+		}
+	}
+
+	// 相同种子下结果可复现
+	replay := newTestPlugin(t, 4, Options{ChaosSeed: 42, DeviceOverrides: overrides})
+	replayFailures := make(map[string]int)
+	for i := 0; i < ticks; i++ {
+		for _, deviceID := range replay.rollDeviceFailures() {
+			replayFailures[deviceID]++
+		}
+	}
+	for deviceID := range probabilities {
+		if replayFailures[deviceID] != failures[deviceID] {
+			t.Errorf("Expected %s to fail %d times with the same seed, got %d", deviceID, failures[deviceID], replayFailures[deviceID])
+		}
+	}
+}
+
+// TestHealthCheckInjectsFailures 测试健康检查按故障概率将设备标记为不健康
+func TestHealthCheckInjectsFailures(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{
+		HealthCheckInterval: 10 * time.Millisecond,
+		DeviceOverrides:     map[string]DeviceOverride{"ppu-1": {FailureProbability: 1}},
+	})
+
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	select {
+	case device := <-plugin.health:
+		if device.ID != "ppu-1" || device.Health != v1beta1.Unhealthy {
+			t.Errorf("Expected ppu-1 to fail, got %s %s", device.ID, device.Health)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Health check did not inject a failure")
+	}
+}
+
+// TestDeadDevicesNeverRecover 测试永久损坏的设备经过健康检查后仍上报为不健康，且分配被拒绝
+func TestDeadDevicesNeverRecover(t *testing.T) {
+	source := &fakeHealthSource{events: make(chan DeviceHealthEvent)}
+	plugin := newTestPlugin(t, 4, Options{HealthSource: source, DeadDevices: []string{"ppu-2"}})
+	stream := runListAndWatch(t, plugin)
+
+	for _, device := range stream.next(t).Devices {
+		if device.ID == "ppu-2" && device.Health != v1beta1.Unhealthy {
+			t.Fatalf("Expected ppu-2 to start Unhealthy, got %s", device.Health)
+		}
+	}
+
+	plugin.StartHealthCheck()
+
+	// 一个健康周期：尝试恢复损坏的设备，并让另一个设备故障以触发上报
+	source.events <- DeviceHealthEvent{ID: "ppu-2", Health: v1beta1.Healthy}
+	source.events <- DeviceHealthEvent{ID: "ppu-1", Health: v1beta1.Unhealthy}
+
+	response := stream.next(t)
+	health := make(map[string]string, len(response.Devices))
+	for _, device := range response.Devices {
+		health[device.ID] = device.Health
+	}
+	if health["ppu-1"] != v1beta1.Unhealthy {
+		t.Fatalf("Expected the update to report ppu-1 Unhealthy, got %v", health)
+	}
+	if health["ppu-2"] != v1beta1.Unhealthy {
+		t.Errorf("Expected dead ppu-2 to stay Unhealthy in the watch stream, got %s", health["ppu-2"])
+	}
+
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-2"}}},
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition allocating a dead device, got %v", err)
+	}
+}
+
+// TestHealthJitter 测试健康检查周期在配置的抖动范围内随机变化，未配置抖动时保持固定
+func TestHealthJitter(t *testing.T) {
+	interval := 100 * time.Millisecond
+	plugin := newTestPlugin(t, 1, Options{HealthJitter: 0.1, ChaosSeed: 1})
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		next := plugin.jitteredInterval(interval)
+		if next < 90*time.Millisecond || next > 110*time.Millisecond {
+			t.Fatalf("Interval %s outside the ±10%% band around %s", next, interval)
+		}
+		seen[next] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected successive intervals to vary, got %v", seen)
+	}
+
+	fixed := newTestPlugin(t, 1, Options{})
+	if got := fixed.jitteredInterval(interval); got != interval {
+		t.Errorf("Expected %s without jitter, got %s", interval, got)
+	}
+}
+
+// TestHealthUpdatesDropped 测试健康通道已满时丢弃更新并增加丢弃计数
+func TestHealthUpdatesDropped(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{HealthBufferSize: 1, HealthSendTimeout: 10 * time.Millisecond})
+
+	for _, deviceID := range []string{"ppu-0", "ppu-1", "ppu-2"} {
+		plugin.applyHealthEvent(DeviceHealthEvent{ID: deviceID, Health: v1beta1.Unhealthy})
+	}
+
+	if got := testutil.ToFloat64(plugin.metrics.healthUpdatesDropped); got != 2 {
+		t.Errorf("Expected 2 dropped health updates, got %v", got)
+	}
+	if device := <-plugin.health; device.ID != "ppu-0" {
+		t.Errorf("Expected the buffered update for ppu-0, got %s", device.ID)
+	}
+	if health, _ := plugin.deviceHealth("ppu-2"); health != v1beta1.Unhealthy {
+		t.Errorf("Expected ppu-2 to be unhealthy despite the dropped update, got %s", health)
+	}
+}
@@ -0,0 +1,125 @@
+package deviceplugin
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// defaultHealthCommandTimeout 默认的健康检查命令单次执行超时
+	defaultHealthCommandTimeout = 5 * time.Second
+	// maxConcurrentHealthProbes 同时执行的健康检查命令数量上限，避免单个卡住的命令拖慢整个周期
+	maxConcurrentHealthProbes = 16
+)
+
+// commandHealthSource 通过外部命令探测设备健康状态，命令以设备ID作为$1，退出码为0表示健康
+type commandHealthSource struct {
+	plugin   *PPUDevicePlugin
+	command  string
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// Watch 每个周期对所有设备执行一次健康检查命令
+func (s *commandHealthSource) Watch(ctx context.Context) <-chan DeviceHealthEvent {
+	out := make(chan DeviceHealthEvent)
+
+	go func() {
+		defer close(out)
+
+		timer := time.NewTimer(s.plugin.jitteredInterval(s.interval))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				timer.Reset(s.plugin.jitteredInterval(s.interval))
+				for _, event := range s.probeAll(ctx, s.plugin.probeDeviceIDs()) {
+					select {
+					case out <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// probeAll 并发地对设备执行健康检查命令，最多同时执行maxConcurrentHealthProbes个，按设备顺序返回结果
+func (s *commandHealthSource) probeAll(ctx context.Context, deviceIDs []string) []DeviceHealthEvent {
+	events := make([]DeviceHealthEvent, len(deviceIDs))
+	sem := make(chan struct{}, maxConcurrentHealthProbes)
+	var wg sync.WaitGroup
+	for i, deviceID := range deviceIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			events[i] = DeviceHealthEvent{ID: deviceID, Health: s.probe(ctx, deviceID)}
+		}()
+	}
+	wg.Wait()
+	return events
+}
+
+// probe 执行一次健康检查命令，非零退出码、执行失败或超时均视为不健康
+func (s *commandHealthSource) probe(ctx context.Context, deviceID string) string {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// 追加"$@"使命令既可以是脚本路径，也可以是带参数的命令行
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command+` "$@"`, "sh", deviceID)
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil {
+		return v1beta1.Healthy
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		s.plugin.log.Warnf("Health command for device %s timed out after %s", deviceID, s.timeout)
+	case errors.As(err, &exitErr):
+		s.plugin.log.Debugf("Health command for device %s exited with code %d", deviceID, exitErr.ExitCode())
+	default:
+		s.plugin.log.Warnf("Failed to run health command for device %s: %v", deviceID, err)
+	}
+	return v1beta1.Unhealthy
+}
+
+// probeDeviceIDs 返回需要执行健康检查的设备ID，注入的不健康设备和永久损坏的设备不参与检查
+func (p *PPUDevicePlugin) probeDeviceIDs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ids := make([]string, 0, len(p.devices))
+	for deviceID := range p.devices {
+		if !p.stickyUnhealthy[deviceID] && !p.permanentlyDead[deviceID] {
+			ids = append(ids, deviceID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+	return ids
+}
+
+// healthCommandTimeout 返回健康检查命令的执行超时，未配置时使用默认值
+func (p *PPUDevicePlugin) healthCommandTimeout() time.Duration {
+	if p.opts.HealthCommandTimeout <= 0 {
+		return defaultHealthCommandTimeout
+	}
+	return p.opts.HealthCommandTimeout
+}
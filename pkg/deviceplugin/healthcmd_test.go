@@ -0,0 +1,95 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// waitHealth 等待设备变为指定的健康状态
+func waitHealth(t *testing.T, plugin *PPUDevicePlugin, deviceID, health string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if got, _ := plugin.deviceHealth(deviceID); got == health {
+			return
+		}
+		if time.Now().After(deadline) {
+			got, _ := plugin.deviceHealth(deviceID)
+			t.Fatalf("Expected %s to become %s, still %s", deviceID, health, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestHealthCommand 测试健康检查命令根据外部文件切换设备健康状态
+func TestHealthCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "probe.sh")
+	content := fmt.Sprintf("#!/bin/sh\n[ ! -e %q/\"$1\".down ]\n", dir)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write probe script: %v", err)
+	}
+
+	plugin := newTestPlugin(t, 2, Options{
+		HealthCheckInterval: 10 * time.Millisecond,
+		HealthCommand:       script,
+	})
+	plugin.StartHealthCheck()
+	defer close(plugin.stop)
+
+	marker := filepath.Join(dir, "ppu-1.down")
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatalf("Failed to create marker: %v", err)
+	}
+	waitHealth(t, plugin, "ppu-1", v1beta1.Unhealthy)
+	if got, _ := plugin.deviceHealth("ppu-0"); got != v1beta1.Healthy {
+		t.Errorf("Expected ppu-0 to stay Healthy, got %s", got)
+	}
+
+	if err := os.Remove(marker); err != nil {
+		t.Fatalf("Failed to remove marker: %v", err)
+	}
+	waitHealth(t, plugin, "ppu-1", v1beta1.Healthy)
+}
+
+// TestHealthCommandTimeout 测试健康检查命令超时视为不健康
+func TestHealthCommandTimeout(t *testing.T) {
+	plugin := newTestPlugin(t, 1, Options{})
+	source := &commandHealthSource{plugin: plugin, command: "sleep 5; true", timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	if health := source.probe(context.Background(), "ppu-0"); health != v1beta1.Unhealthy {
+		t.Errorf("Expected a timed-out probe to be Unhealthy, got %s", health)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the probe to be killed after the timeout, took %s", elapsed)
+	}
+}
+
+// TestHealthCommandConcurrentProbes 测试健康检查命令并发执行，慢命令不会按设备数量累加整个周期的耗时
+func TestHealthCommandConcurrentProbes(t *testing.T) {
+	plugin := newTestPlugin(t, 8, Options{})
+	source := &commandHealthSource{plugin: plugin, command: "sleep 0.3; true", timeout: 5 * time.Second}
+
+	start := time.Now()
+	events := source.probeAll(context.Background(), plugin.probeDeviceIDs())
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("Expected probes to run concurrently, took %s", elapsed)
+	}
+
+	if len(events) != 8 {
+		t.Fatalf("Expected 8 probe results, got %d", len(events))
+	}
+	for i, event := range events {
+		if expected := fmt.Sprintf("ppu-%d", i); event.ID != expected || event.Health != v1beta1.Healthy {
+			t.Errorf("Expected %s to be Healthy in device order, got %+v", expected, event)
+		}
+	}
+}
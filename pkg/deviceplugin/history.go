@@ -0,0 +1,75 @@
+package deviceplugin
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHistorySize 默认保留的分配记录条数
+const defaultHistorySize = 100
+
+const (
+	// OutcomeSuccess 分配成功
+	OutcomeSuccess = "success"
+	// OutcomeError 分配失败
+	OutcomeError = "error"
+)
+
+// AllocationRecord 一个容器请求的分配记录
+type AllocationRecord struct {
+	Time      time.Time `json:"time"`
+	Container string    `json:"container"`
+	Devices   []string  `json:"devices"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// allocationOutcome 根据Allocate返回的错误得到分配结果
+func allocationOutcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// allocationHistory 并发安全的分配记录环形缓冲区
+type allocationHistory struct {
+	mu      sync.Mutex
+	records []AllocationRecord
+	next    int
+	full    bool
+}
+
+// newAllocationHistory 创建指定容量的分配记录缓冲区
+func newAllocationHistory(size int) *allocationHistory {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &allocationHistory{records: make([]AllocationRecord, size)}
+}
+
+// add 追加一条记录，缓冲区满时覆盖最旧的记录
+func (h *allocationHistory) add(record AllocationRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list 按时间顺序返回所有记录
+func (h *allocationHistory) list() []AllocationRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]AllocationRecord{}, h.records[:h.next]...)
+	}
+
+	records := make([]AllocationRecord, 0, len(h.records))
+	records = append(records, h.records[h.next:]...)
+	return append(records, h.records[:h.next]...)
+}
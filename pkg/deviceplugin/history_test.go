@@ -0,0 +1,18 @@
+package deviceplugin
+
+import (
+	"testing"
+)
+
+// TestAllocationHistoryWraps 测试环形缓冲区满后覆盖最旧的记录
+func TestAllocationHistoryWraps(t *testing.T) {
+	history := newAllocationHistory(2)
+	for _, container := range []string{"a", "b", "c"} {
+		history.add(AllocationRecord{Container: container})
+	}
+
+	records := history.list()
+	if len(records) != 2 || records[0].Container != "b" || records[1].Container != "c" {
+		t.Errorf("Expected records [b c], got %+v", records)
+	}
+}
@@ -0,0 +1,72 @@
+package deviceplugin
+
+import (
+	"fmt"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// AllocationHook 在Allocate中对每个已分配设备调用，可用于定制容器分配响应（如追加设备相关的挂载或环境变量）
+type AllocationHook func(deviceID string, resp *v1beta1.ContainerAllocateResponse) error
+
+// RegisterAllocationHook 注册分配钩子，钩子按注册顺序执行
+func (p *PPUDevicePlugin) RegisterAllocationHook(hook AllocationHook) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.allocationHooks = append(p.allocationHooks, hook)
+}
+
+// runAllocationHooks 对容器分配到的每个设备依次执行所有钩子，遇到错误立即返回
+func (p *PPUDevicePlugin) runAllocationHooks(deviceIDs []string, resp *v1beta1.ContainerAllocateResponse) error {
+	p.mu.RLock()
+	hooks := append([]AllocationHook{}, p.allocationHooks...)
+	p.mu.RUnlock()
+
+	for _, deviceID := range deviceIDs {
+		for i, hook := range hooks {
+			if err := hook(deviceID, resp); err != nil {
+				return fmt.Errorf("hook %d for device %s: %v", i, deviceID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeviceSource 自定义设备列表来源，嵌入方据此自行决定ListAndWatch上报的设备
+type DeviceSource func() []*v1beta1.Device
+
+// SetDeviceSource 设置ListAndWatch使用的设备列表来源，传入nil恢复为上报插件自身的设备；
+// 设置后上报的列表完全由来源决定，不再应用预留、排序等处理
+func (p *PPUDevicePlugin) SetDeviceSource(source DeviceSource) {
+	p.mu.Lock()
+	p.deviceSource = source
+	p.mu.Unlock()
+
+	p.TriggerUpdate()
+}
+
+// TriggerUpdate 通知ListAndWatch立即重新生成并上报设备列表
+func (p *PPUDevicePlugin) TriggerUpdate() {
+	p.notifyListAndWatch()
+}
+
+// sourcedDeviceList 从自定义来源获取设备列表的副本，未设置来源时返回false
+func (p *PPUDevicePlugin) sourcedDeviceList() ([]*v1beta1.Device, bool) {
+	p.mu.RLock()
+	source := p.deviceSource
+	p.mu.RUnlock()
+
+	if source == nil {
+		return nil, false
+	}
+
+	// 调用来源时不持有锁，来源可以安全地回调插件的方法
+	sourced := source()
+	devices := make([]*v1beta1.Device, 0, len(sourced))
+	for _, device := range sourced {
+		devices = append(devices, copyDevice(device))
+	}
+	return devices, true
+}
@@ -0,0 +1,96 @@
+package deviceplugin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestAllocationHooks 测试分配钩子按注册顺序执行，且错误会中止分配
+func TestAllocationHooks(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	order := []string{}
+	plugin.RegisterAllocationHook(func(deviceID string, resp *v1beta1.ContainerAllocateResponse) error {
+		order = append(order, "first:"+deviceID)
+		resp.Envs["PPU_HOOK_"+deviceID] = "ok"
+		return nil
+	})
+	plugin.RegisterAllocationHook(func(deviceID string, resp *v1beta1.ContainerAllocateResponse) error {
+		order = append(order, "second:"+deviceID)
+		return nil
+	})
+
+	response, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	if got := response.ContainerResponses[0].Envs["PPU_HOOK_ppu-0"]; got != "ok" {
+		t.Errorf("Expected hook env to be set, got %q", got)
+	}
+	if len(order) != 2 || order[0] != "first:ppu-0" || order[1] != "second:ppu-0" {
+		t.Errorf("Expected hooks to run in registration order, got %v", order)
+	}
+
+	plugin.RegisterAllocationHook(func(deviceID string, resp *v1beta1.ContainerAllocateResponse) error {
+		return errors.New("boom")
+	})
+
+	_, err = plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-1"}}},
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal error from failing hook, got %v", err)
+	}
+	if owner := plugin.allocated["ppu-1"]; owner != "" {
+		t.Errorf("Expected aborted allocation not to claim ppu-1, got owner %q", owner)
+	}
+}
+
+// TestDeviceSource 测试设置设备来源后ListAndWatch上报来源提供的列表，TriggerUpdate强制重新上报
+func TestDeviceSource(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+	stream := runListAndWatch(t, plugin)
+	if got := len(stream.next(t).Devices); got != 4 {
+		t.Fatalf("Expected the plugin's own 4 devices by default, got %d", got)
+	}
+
+	var mu sync.Mutex
+	sourced := []*v1beta1.Device{{ID: "custom-0", Health: v1beta1.Healthy}}
+	plugin.SetDeviceSource(func() []*v1beta1.Device {
+		mu.Lock()
+		defer mu.Unlock()
+		return sourced
+	})
+
+	if ids := advertisedIDs(stream.next(t)); !reflect.DeepEqual(ids, []string{"custom-0"}) {
+		t.Errorf("Expected the sourced device list, got %v", ids)
+	}
+
+	mu.Lock()
+	sourced = append(sourced, &v1beta1.Device{ID: "custom-1", Health: v1beta1.Unhealthy})
+	mu.Unlock()
+	plugin.TriggerUpdate()
+
+	response := stream.next(t)
+	if ids := advertisedIDs(response); !reflect.DeepEqual(ids, []string{"custom-0", "custom-1"}) {
+		t.Errorf("Expected TriggerUpdate to send the updated source, got %v", ids)
+	}
+	if response.Devices[1].Health != v1beta1.Unhealthy {
+		t.Errorf("Expected sourced health to be preserved, got %s", response.Devices[1].Health)
+	}
+
+	plugin.SetDeviceSource(nil)
+	if got := len(stream.next(t).Devices); got != 4 {
+		t.Errorf("Expected clearing the source to restore the plugin's devices, got %d", got)
+	}
+}
@@ -0,0 +1,81 @@
+package deviceplugin
+
+// DeviceCount 返回当前的物理设备数量
+func (p *PPUDevicePlugin) DeviceCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.deviceCount
+}
+
+// SetDeviceCount 热插拔模拟：按新的设备数量添加或移除设备，并通知ListAndWatch重新上报。
+// 移除已分配的设备时记录警告，但仍会移除
+func (p *PPUDevicePlugin) SetDeviceCount(count int) error {
+	if err := ValidateDeviceCount(count, p.opts.MaxDeviceCount); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	previous := p.deviceCount
+	p.deviceCount = count
+	p.mu.Unlock()
+
+	if count == previous {
+		p.log.Debugf("Device count unchanged at %d", count)
+		return nil
+	}
+
+	for i := previous; i < count; i++ {
+		p.addDevice(i)
+	}
+	released := false
+	for i := count; i < previous; i++ {
+		if p.removeDevice(i) {
+			released = true
+		}
+	}
+	if released {
+		p.saveState()
+	}
+
+	p.mu.Lock()
+	p.updateDeviceGaugesLocked()
+	p.mu.Unlock()
+
+	p.log.Infof("Device count changed from %d to %d", previous, count)
+	p.notifyListAndWatch()
+	return nil
+}
+
+// removeDevice 移除序号为index的物理设备及其全部分区，返回是否释放了分配记录
+func (p *PPUDevicePlugin) removeDevice(index int) bool {
+	parentID := p.deviceIDForIndex(index)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	released := false
+	for _, deviceID := range partitionIDs(parentID, p.opts.PartitionsPerDevice) {
+		if owner, allocated := p.allocated[deviceID]; allocated {
+			p.log.Warnf("Removing device %s while it is allocated to %s", deviceID, owner)
+			released = true
+		}
+
+		delete(p.devices, deviceID)
+		delete(p.allocated, deviceID)
+		delete(p.busyUntil, deviceID)
+		delete(p.reserved, deviceID)
+		delete(p.stickyUnhealthy, deviceID)
+		delete(p.permanentlyDead, deviceID)
+		delete(p.parents, deviceID)
+		p.invalidateAllocationCacheLocked(deviceID)
+		delete(p.utilization, deviceID)
+		delete(p.temperature, deviceID)
+		delete(p.attributes, deviceID)
+		p.metrics.deviceUtilization.DeleteLabelValues(deviceID)
+		p.log.Debugf("Removed PPU device: %s", deviceID)
+	}
+	// indexes以物理设备ID为键，分区全部移除后再删除
+	delete(p.indexes, parentID)
+	return released
+}
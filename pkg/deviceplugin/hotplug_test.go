@@ -0,0 +1,87 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// TestSetDeviceCount 测试调整设备数量后设备表增减，并重新上报给ListAndWatch
+func TestSetDeviceCount(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{NUMANodes: 2})
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	if err := plugin.SetDeviceCount(4); err != nil {
+		t.Fatalf("SetDeviceCount(4) failed: %v", err)
+	}
+	if len(plugin.devices) != 4 {
+		t.Fatalf("Expected 4 devices after growing, got %d", len(plugin.devices))
+	}
+	if node := plugin.devices["ppu-3"].Topology.Nodes[0].ID; node != 1 {
+		t.Errorf("Expected hot-plugged ppu-3 on NUMA node 1, got %d", node)
+	}
+
+	ids := advertisedIDs(stream.next(t))
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+	if expected := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected re-advertised devices %v, got %v", expected, ids)
+	}
+
+	allocate(t, plugin, "ppu-2")
+
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	if err := plugin.SetDeviceCount(1); err != nil {
+		t.Fatalf("SetDeviceCount(1) failed: %v", err)
+	}
+	if len(plugin.devices) != 1 || plugin.devices["ppu-0"] == nil {
+		t.Fatalf("Expected only ppu-0 after shrinking, got %v", plugin.devices)
+	}
+	if _, allocated := plugin.allocated["ppu-2"]; allocated {
+		t.Error("Expected the allocation of a removed device to be dropped")
+	}
+	if ids := advertisedIDs(stream.next(t)); !reflect.DeepEqual(ids, []string{"ppu-0"}) {
+		t.Errorf("Expected re-advertised devices [ppu-0], got %v", ids)
+	}
+
+	warned := false
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == log.WarnLevel && strings.Contains(entry.Message, "ppu-2") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Error("Expected a warning when removing an allocated device")
+	}
+
+	if err := plugin.SetDeviceCount(0); err == nil {
+		t.Error("Expected an error for a non-positive device count")
+	}
+}
+
+// TestSetDeviceCountPartitioned 测试启用分区时缩减设备数量会移除物理设备的全部分区及其序号映射
+func TestSetDeviceCountPartitioned(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{PartitionsPerDevice: 2})
+
+	if err := plugin.SetDeviceCount(1); err != nil {
+		t.Fatalf("SetDeviceCount(1) failed: %v", err)
+	}
+	if ids := deviceIDs(plugin); !reflect.DeepEqual(ids, []string{"ppu-0-1", "ppu-0-2"}) {
+		t.Errorf("Expected only the partitions of ppu-0 after shrinking, got %v", ids)
+	}
+	if _, exists := plugin.indexes["ppu-1"]; exists {
+		t.Error("Expected the index of removed ppu-1 to be dropped")
+	}
+	if _, exists := plugin.indexes["ppu-0"]; !exists {
+		t.Error("Expected the index of remaining ppu-0 to be kept")
+	}
+	if len(plugin.parents) != 2 {
+		t.Errorf("Expected 2 partition parents after shrinking, got %v", plugin.parents)
+	}
+}
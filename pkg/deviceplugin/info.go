@@ -0,0 +1,42 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"runtime"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// BuildInfo 构建时通过ldflags注入的版本信息
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Info 运行实例的版本信息
+type Info struct {
+	BuildInfo
+	GoVersion    string `json:"goVersion"`
+	APIVersion   string `json:"apiVersion"`
+	ResourceName string `json:"resourceName"`
+}
+
+// Info 返回插件的构建信息及声明的设备插件API版本
+func (p *PPUDevicePlugin) Info() Info {
+	return Info{
+		BuildInfo:    p.opts.BuildInfo,
+		GoVersion:    runtime.Version(),
+		APIVersion:   v1beta1.Version,
+		ResourceName: p.resourceName,
+	}
+}
+
+// handleInfo 返回插件的版本信息
+func (p *PPUDevicePlugin) handleInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, p.log, http.StatusOK, p.Info())
+}
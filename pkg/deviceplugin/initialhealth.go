@@ -0,0 +1,119 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// HealthCount 连续若干个设备的初始健康状态
+type HealthCount struct {
+	Health string
+	Count  int
+}
+
+// InitialHealth 启动时的设备健康状态分布
+type InitialHealth struct {
+	// Counts 按设备顺序依次分配的健康状态，如healthy:12,unhealthy:4表示前12个设备健康、之后4个不健康
+	Counts []HealthCount
+	// Devices 按设备ID指定的健康状态，优先于Counts
+	Devices map[string]string
+}
+
+// ParseInitialHealth 解析初始健康状态分布，格式为 "healthy:12,unhealthy:4" 或 "ppu-0:unhealthy,ppu-3:healthy"，两种写法可以混用
+func ParseInitialHealth(spec string) (InitialHealth, error) {
+	initial := InitialHealth{Devices: make(map[string]string)}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(item, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return InitialHealth{}, fmt.Errorf("invalid initial health %q: expected state:count or id:state", item)
+		}
+
+		if health, isState := parseHealthState(key); isState {
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 0 {
+				return InitialHealth{}, fmt.Errorf("invalid initial health %q: count must be a non-negative integer", item)
+			}
+			initial.Counts = append(initial.Counts, HealthCount{Health: health, Count: count})
+			continue
+		}
+
+		health, isState := parseHealthState(value)
+		if !isState {
+			return InitialHealth{}, fmt.Errorf("invalid initial health %q: state must be healthy or unhealthy", item)
+		}
+		if _, exists := initial.Devices[key]; exists {
+			return InitialHealth{}, fmt.Errorf("device %s appears more than once", key)
+		}
+		initial.Devices[key] = health
+	}
+
+	return initial, nil
+}
+
+// parseHealthState 将healthy/unhealthy（不区分大小写）转换为设备插件API的健康状态
+func parseHealthState(s string) (string, bool) {
+	switch strings.ToLower(s) {
+	case "healthy":
+		return v1beta1.Healthy, true
+	case "unhealthy":
+		return v1beta1.Unhealthy, true
+	}
+	return "", false
+}
+
+// applyInitialHealth 按配置的初始健康状态分布设置设备健康状态；
+// 未启用InitialHealthTransient时不健康的设备不会被健康检查自动恢复
+func (p *PPUDevicePlugin) applyInitialHealth(deviceIDs []string) {
+	initial := p.opts.InitialHealth
+	if len(initial.Counts) == 0 && len(initial.Devices) == 0 {
+		return
+	}
+
+	states := make(map[string]string, len(deviceIDs))
+	next := 0
+	for _, run := range initial.Counts {
+		for i := 0; i < run.Count; i++ {
+			if next >= len(deviceIDs) {
+				p.log.Warnf("Initial health counts exceed the %d devices, ignoring the rest", len(deviceIDs))
+				break
+			}
+			states[deviceIDs[next]] = run.Health
+			next++
+		}
+	}
+	known := make(map[string]bool, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		known[deviceID] = true
+	}
+	for deviceID, health := range initial.Devices {
+		if !known[deviceID] {
+			p.log.Warnf("Initial health for device %s does not match any device, ignoring", deviceID)
+			continue
+		}
+		states[deviceID] = health
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for deviceID, health := range states {
+		p.devices[deviceID].Health = health
+		if health == v1beta1.Unhealthy && !p.opts.InitialHealthTransient {
+			p.stickyUnhealthy[deviceID] = true
+		} else {
+			delete(p.stickyUnhealthy, deviceID)
+		}
+	}
+	p.log.Infof("Applied initial health to %d devices", len(states))
+	p.updateDeviceGaugesLocked()
+}
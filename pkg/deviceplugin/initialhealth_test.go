@@ -0,0 +1,69 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestParseInitialHealth 测试初始健康状态分布的解析
+func TestParseInitialHealth(t *testing.T) {
+	initial, err := ParseInitialHealth("healthy:12, Unhealthy:4,ppu-0:unhealthy")
+	if err != nil {
+		t.Fatalf("ParseInitialHealth failed: %v", err)
+	}
+	expectedCounts := []HealthCount{{Health: v1beta1.Healthy, Count: 12}, {Health: v1beta1.Unhealthy, Count: 4}}
+	if !reflect.DeepEqual(initial.Counts, expectedCounts) {
+		t.Errorf("Expected counts %v, got %v", expectedCounts, initial.Counts)
+	}
+	if !reflect.DeepEqual(initial.Devices, map[string]string{"ppu-0": v1beta1.Unhealthy}) {
+		t.Errorf("Unexpected per-device health %v", initial.Devices)
+	}
+
+	for _, spec := range []string{"healthy", "healthy:x", "unhealthy:-1", "ppu-0:broken", "ppu-0:healthy,ppu-0:unhealthy"} {
+		if _, err := ParseInitialHealth(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+// TestInitialHealthCounts 测试按数量分布的初始健康状态反映在首次上报的设备列表中且保持不健康
+func TestInitialHealthCounts(t *testing.T) {
+	initial, err := ParseInitialHealth("healthy:12,unhealthy:4")
+	if err != nil {
+		t.Fatalf("ParseInitialHealth failed: %v", err)
+	}
+	plugin := newTestPlugin(t, 16, Options{InitialHealth: initial})
+
+	unhealthy := []string{}
+	for _, device := range runListAndWatch(t, plugin).next(t).Devices {
+		if device.Health == v1beta1.Unhealthy {
+			unhealthy = append(unhealthy, device.ID)
+		}
+	}
+	sort.Slice(unhealthy, func(i, j int) bool { return deviceIDLess(unhealthy[i], unhealthy[j]) })
+	if expected := []string{"ppu-12", "ppu-13", "ppu-14", "ppu-15"}; !reflect.DeepEqual(unhealthy, expected) {
+		t.Errorf("Expected unhealthy devices %v, got %v", expected, unhealthy)
+	}
+
+	if ids := plugin.recoverableDeviceIDs(); len(ids) != 0 {
+		t.Errorf("Expected initial unhealthy devices to be sticky, got recoverable %v", ids)
+	}
+}
+
+// TestInitialHealthTransient 测试启用transient后初始不健康的设备可以被健康检查恢复
+func TestInitialHealthTransient(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{
+		InitialHealth:          InitialHealth{Devices: map[string]string{"ppu-2": v1beta1.Unhealthy}},
+		InitialHealthTransient: true,
+	})
+
+	if health, _ := plugin.deviceHealth("ppu-2"); health != v1beta1.Unhealthy {
+		t.Fatalf("Expected ppu-2 to start unhealthy, got %s", health)
+	}
+	if ids := plugin.recoverableDeviceIDs(); !reflect.DeepEqual(ids, []string{"ppu-2"}) {
+		t.Errorf("Expected ppu-2 to be recoverable, got %v", ids)
+	}
+}
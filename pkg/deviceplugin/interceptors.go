@@ -0,0 +1,66 @@
+package deviceplugin
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serverOptions 返回插件gRPC服务器的选项：恢复panic的拦截器最先执行，其次是调试日志，最后是Options中配置的拦截器
+func (p *PPUDevicePlugin) serverOptions() []grpc.ServerOption {
+	unary := append([]grpc.UnaryServerInterceptor{p.recoverUnary, p.logUnary}, p.opts.UnaryInterceptors...)
+	stream := append([]grpc.StreamServerInterceptor{p.recoverStream, p.logStream}, p.opts.StreamInterceptors...)
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+// recoverUnary 将一元调用处理中的panic转换为Internal错误，避免整个gRPC服务器崩溃
+func (p *PPUDevicePlugin) recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.recoveredError(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoverStream 将流式调用处理中的panic转换为Internal错误
+func (p *PPUDevicePlugin) recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.recoveredError(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// recoveredError 记录panic及其调用栈，返回Internal错误
+func (p *PPUDevicePlugin) recoveredError(method string, r interface{}) error {
+	p.log.Errorf("Recovered from panic in %s: %v\n%s", method, r, debug.Stack())
+	return status.Errorf(codes.Internal, "internal error in %s: %v", method, r)
+}
+
+// logUnary 在调试级别记录一元调用的请求和响应
+func (p *PPUDevicePlugin) logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	p.log.Debugf("gRPC request %s: %+v", info.FullMethod, req)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		p.log.Debugf("gRPC %s failed: %v", info.FullMethod, err)
+	} else {
+		p.log.Debugf("gRPC response %s: %+v", info.FullMethod, resp)
+	}
+	return resp, err
+}
+
+// logStream 在调试级别记录流式调用的开始和结束
+func (p *PPUDevicePlugin) logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	p.log.Debugf("gRPC stream %s started", info.FullMethod)
+	err := handler(srv, ss)
+	p.log.Debugf("gRPC stream %s finished: %v", info.FullMethod, err)
+	return err
+}
@@ -0,0 +1,64 @@
+package deviceplugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestRecoverHandlerPanic 测试处理函数panic时返回Internal错误，且服务器继续处理后续请求
+func TestRecoverHandlerPanic(t *testing.T) {
+	panicUnary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/v1beta1.DevicePlugin/Allocate" {
+			panic("injected allocate panic")
+		}
+		return handler(ctx, req)
+	}
+	panicStream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		panic("injected stream panic")
+	}
+
+	tmpDir := t.TempDir()
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{
+		NoRegister:         true,
+		UnaryInterceptors:  []grpc.UnaryServerInterceptor{panicUnary},
+		StreamInterceptors: []grpc.StreamServerInterceptor{panicStream},
+	})
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop()
+
+	conn, err := plugin.dial(context.Background(), plugin.Socket(), time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to plugin socket: %v", err)
+	}
+	defer conn.Close()
+	client := v1beta1.NewDevicePluginClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.Allocate(ctx, &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal from a panicking Allocate, got %v", err)
+	}
+
+	stream, err := client.ListAndWatch(ctx, &v1beta1.Empty{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal from a panicking ListAndWatch, got %v", err)
+	}
+
+	if _, err := client.GetDevicePluginOptions(ctx, &v1beta1.Empty{}); err != nil {
+		t.Errorf("Expected the server to survive the panics, got %v", err)
+	}
+}
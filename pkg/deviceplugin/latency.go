@@ -0,0 +1,62 @@
+package deviceplugin
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// allocationDelay 计算一次分配请求的模拟延迟：基础延迟、随机抖动加上按设备数量递增的延迟
+func (p *PPUDevicePlugin) allocationDelay(request *v1beta1.AllocateRequest) time.Duration {
+	deviceCount := 0
+	for _, containerRequest := range request.ContainerRequests {
+		deviceCount += len(containerRequest.DevicesIDs)
+	}
+
+	return p.opts.AllocateLatency + p.allocationJitter() + time.Duration(deviceCount)*p.opts.AllocationDelayPerDevice
+}
+
+// allocationJitter 返回[0, AllocateLatencyJitter)内的随机延迟
+func (p *PPUDevicePlugin) allocationJitter() time.Duration {
+	if p.opts.AllocateLatencyJitter <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Duration(p.rng.Int63n(int64(p.opts.AllocateLatencyJitter)))
+}
+
+// injectAllocationDelay 模拟驱动初始化设备的耗时，上下文取消时立即返回
+func (p *PPUDevicePlugin) injectAllocationDelay(ctx context.Context, request *v1beta1.AllocateRequest) error {
+	delay := p.allocationDelay(request)
+	if delay <= 0 {
+		return nil
+	}
+
+	p.log.Debugf("Injecting allocation delay of %s", delay)
+	return sleepContext(ctx, delay)
+}
+
+// contextError 在ctx已取消或超时时返回对应的gRPC状态错误，否则返回nil
+func contextError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// sleepContext 等待指定时长，上下文取消时返回ctx.Err()，由gRPC服务端转换为对应的状态码
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,51 @@
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SetMaintenance 设置维护模式：维护期间ListAndWatch上报空的设备列表，插件保持注册，
+// 使节点仍有该资源但可分配数量为零；退出维护后重新上报全部设备
+func (p *PPUDevicePlugin) SetMaintenance(enabled bool) {
+	if p.maintenance.Swap(enabled) == enabled {
+		return
+	}
+
+	if enabled {
+		p.log.Infof("Entering maintenance mode for %s: advertising no devices", p.resourceName)
+	} else {
+		p.log.Infof("Leaving maintenance mode for %s: advertising devices again", p.resourceName)
+	}
+	p.notifyListAndWatch()
+}
+
+// Maintenance 返回插件是否处于维护模式
+func (p *PPUDevicePlugin) Maintenance() bool {
+	return p.maintenance.Load()
+}
+
+// maintenanceRequest POST /maintenance的请求体
+type maintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// handleMaintenance 处理GET /maintenance查询维护模式，POST /maintenance以{"enabled": true}进入或退出维护模式
+func (p *PPUDevicePlugin) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var request maintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		p.SetMaintenance(request.Enabled)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, p.log, http.StatusOK, map[string]bool{"maintenance": p.Maintenance()})
+}
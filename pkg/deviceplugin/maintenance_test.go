@@ -0,0 +1,32 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestMaintenanceToggle 测试进入维护模式后上报空列表，退出后重新上报全部设备
+func TestMaintenanceToggle(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{Maintenance: true, EnableAdminAPI: true})
+	stream := runListAndWatch(t, plugin)
+
+	if devices := stream.next(t).Devices; len(devices) != 0 {
+		t.Fatalf("Expected an empty device list in maintenance mode, got %d devices", len(devices))
+	}
+
+	plugin.SetMaintenance(false)
+	if devices := stream.next(t).Devices; len(devices) != 4 {
+		t.Errorf("Expected 4 devices after leaving maintenance, got %d", len(devices))
+	}
+
+	recorder := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/maintenance", strings.NewReader(`{"enabled": true}`)))
+	if recorder.Code != http.StatusOK || !plugin.Maintenance() {
+		t.Fatalf("Expected POST /maintenance to enable maintenance, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if devices := stream.next(t).Devices; len(devices) != 0 {
+		t.Errorf("Expected an empty device list after entering maintenance, got %d devices", len(devices))
+	}
+}
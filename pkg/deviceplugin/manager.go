@@ -0,0 +1,91 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResourceSpec 一个资源名称及其模拟的设备数量
+type ResourceSpec struct {
+	Name  string
+	Count int
+}
+
+// ParseResourceSpec 解析"name:count"格式的资源配置，如alibabacloud.com/ppu-shared:8
+func ParseResourceSpec(spec string) (ResourceSpec, error) {
+	i := strings.LastIndex(spec, ":")
+	if i <= 0 || i == len(spec)-1 {
+		return ResourceSpec{}, fmt.Errorf("invalid resource %q: expected name:count", spec)
+	}
+
+	name := strings.TrimSpace(spec[:i])
+	count, err := strconv.Atoi(strings.TrimSpace(spec[i+1:]))
+	if err != nil || count <= 0 {
+		return ResourceSpec{}, fmt.Errorf("invalid device count in resource %q: must be a positive integer", spec)
+	}
+	if err := ValidateResourceName(name); err != nil {
+		return ResourceSpec{}, err
+	}
+
+	return ResourceSpec{Name: name, Count: count}, nil
+}
+
+// Manager 管理同一进程内的多个设备插件实例，每个实例对应一个资源名称
+type Manager struct {
+	plugins []*PPUDevicePlugin
+}
+
+// NewManager 创建管理指定插件实例的Manager
+func NewManager(plugins ...*PPUDevicePlugin) *Manager {
+	return &Manager{plugins: plugins}
+}
+
+// Plugins 返回管理的插件实例
+func (m *Manager) Plugins() []*PPUDevicePlugin {
+	return m.plugins
+}
+
+// StartContext 依次启动所有插件，任一插件启动失败时停止已启动的插件并返回错误
+func (m *Manager) StartContext(ctx context.Context) error {
+	for i, plugin := range m.plugins {
+		if err := plugin.StartContext(ctx); err != nil {
+			for _, started := range m.plugins[:i] {
+				started.Stop()
+			}
+			return fmt.Errorf("failed to start plugin for %s: %w", plugin.resourceName, err)
+		}
+	}
+	return nil
+}
+
+// StartHealthCheckContext 启动所有插件的健康检查
+func (m *Manager) StartHealthCheckContext(ctx context.Context) {
+	for _, plugin := range m.plugins {
+		plugin.StartHealthCheckContext(ctx)
+	}
+}
+
+// Drain 同时排空所有插件，并只等待一次排空宽限期（取各插件配置的最大值）
+func (m *Manager) Drain(ctx context.Context) {
+	var grace time.Duration
+	for _, plugin := range m.plugins {
+		plugin.beginDrain()
+		if plugin.opts.DrainGrace > grace {
+			grace = plugin.opts.DrainGrace
+		}
+	}
+	waitDrainGrace(ctx, log.NewEntry(log.StandardLogger()), grace)
+}
+
+// Stop 停止所有插件
+func (m *Manager) Stop() {
+	for _, plugin := range m.plugins {
+		log.Infof("Stopping device plugin for %s", plugin.resourceName)
+		plugin.Stop()
+	}
+}
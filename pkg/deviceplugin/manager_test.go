@@ -0,0 +1,74 @@
+package deviceplugin
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestManagerMultipleResources 测试同一进程内的两个资源分别注册并可独立分配
+func TestManagerMultipleResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+
+	ppu := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{SocketName: SocketNameForResource("test.com/ppu")})
+	shared := NewPPUDevicePluginWithOptions("test.com/ppu-shared", 4, tmpDir, Options{SocketName: SocketNameForResource("test.com/ppu-shared")})
+	manager := NewManager(ppu, shared)
+	if err := manager.StartContext(context.Background()); err != nil {
+		t.Fatalf("StartContext failed: %v", err)
+	}
+
+	endpoints := map[string]string{}
+	for i := 0; i < 2; i++ {
+		select {
+		case request := <-kubelet.Requests():
+			endpoints[request.ResourceName] = request.Endpoint
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for registration")
+		}
+	}
+	if endpoints["test.com/ppu"] != "test.com_ppu.sock" || endpoints["test.com/ppu-shared"] != "test.com_ppu-shared.sock" {
+		t.Errorf("Unexpected registration endpoints: %v", endpoints)
+	}
+
+	for _, plugin := range manager.Plugins() {
+		conn, err := plugin.dial(context.Background(), plugin.socket, 5*time.Second)
+		if err != nil {
+			t.Fatalf("Failed to connect to %s: %v", plugin.resourceName, err)
+		}
+		_, err = v1beta1.NewDevicePluginClient(conn).Allocate(context.Background(), &v1beta1.AllocateRequest{
+			ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-1"}}},
+		})
+		conn.Close()
+		if err != nil {
+			t.Errorf("Allocate from %s failed: %v", plugin.resourceName, err)
+		}
+	}
+
+	manager.Stop()
+	for _, plugin := range manager.Plugins() {
+		if _, err := os.Stat(plugin.socket); !os.IsNotExist(err) {
+			t.Errorf("Expected socket %s to be removed after Stop, got %v", plugin.socket, err)
+		}
+	}
+}
+
+// TestParseResourceSpec 测试解析name:count格式的资源配置
+func TestParseResourceSpec(t *testing.T) {
+	spec, err := ParseResourceSpec("alibabacloud.com/ppu-shared:8")
+	if err != nil {
+		t.Fatalf("ParseResourceSpec failed: %v", err)
+	}
+	if spec.Name != "alibabacloud.com/ppu-shared" || spec.Count != 8 {
+		t.Errorf("Unexpected spec: %+v", spec)
+	}
+
+	for _, input := range []string{"alibabacloud.com/ppu", "ppu:4", "alibabacloud.com/ppu:0", "alibabacloud.com/ppu:x", ":4"} {
+		if _, err := ParseResourceSpec(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
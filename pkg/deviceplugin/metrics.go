@@ -0,0 +1,97 @@
+package deviceplugin
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// metrics 设备插件导出的Prometheus指标，每个插件实例使用独立的Registry
+type metrics struct {
+	registry *prometheus.Registry
+
+	allocateRequests *prometheus.CounterVec
+	allocateDuration *prometheus.HistogramVec
+	allocatedDevices prometheus.Counter
+	devicesAllocated prometheus.Gauge
+	unhealthyDevices prometheus.Gauge
+	totalDevices     prometheus.Gauge
+	// healthUpdatesDropped 健康通道已满而丢弃的健康状态更新
+	healthUpdatesDropped prometheus.Counter
+
+	deviceUtilization *prometheus.GaugeVec
+	// deviceAllocations 按设备统计分配次数，设备数量较多时标签基数较大，需显式开启
+	deviceAllocations *prometheus.CounterVec
+}
+
+// newMetrics 创建并注册设备插件指标
+func newMetrics(opts Options) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		allocateRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ppu_allocate_requests_total",
+			Help: "Total number of Allocate requests by outcome.",
+		}, []string{"outcome"}),
+		allocateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ppu_allocate_duration_seconds",
+			Help:    "Duration of Allocate requests by outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		allocatedDevices: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ppu_allocated_devices_total",
+			Help: "Total number of devices handed out by Allocate.",
+		}),
+		devicesAllocated: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ppu_devices_allocated",
+			Help: "Number of PPU devices currently allocated.",
+		}),
+		unhealthyDevices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ppu_unhealthy_devices",
+			Help: "Number of PPU devices currently unhealthy.",
+		}),
+		totalDevices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ppu_total_devices",
+			Help: "Total number of simulated PPU devices.",
+		}),
+		healthUpdatesDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ppu_health_updates_dropped_total",
+			Help: "Total number of device health updates dropped because the health channel was full.",
+		}),
+		deviceUtilization: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ppu_device_utilization",
+			Help: "Simulated utilization percentage of each PPU device.",
+		}, []string{"device_id"}),
+		deviceAllocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ppu_device_allocations_total",
+			Help: "Total number of times each PPU device was allocated.",
+		}, []string{"device_id"}),
+	}
+
+	m.registry.MustRegister(m.allocateRequests, m.allocateDuration, m.allocatedDevices, m.devicesAllocated,
+		m.unhealthyDevices, m.totalDevices, m.healthUpdatesDropped, m.deviceUtilization)
+	if opts.PerDeviceMetrics {
+		m.registry.MustRegister(m.deviceAllocations)
+	}
+	return m
+}
+
+// handler 返回/metrics的HTTP处理器
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// updateDeviceGaugesLocked 根据当前设备状态和分配记录刷新设备数量指标，调用方需持有p.mu
+func (p *PPUDevicePlugin) updateDeviceGaugesLocked() {
+	unhealthy := 0
+	for _, device := range p.devices {
+		if device.Health != v1beta1.Healthy {
+			unhealthy++
+		}
+	}
+
+	p.metrics.totalDevices.Set(float64(len(p.devices)))
+	p.metrics.unhealthyDevices.Set(float64(unhealthy))
+	p.metrics.devicesAllocated.Set(float64(len(p.allocated)))
+}
@@ -0,0 +1,128 @@
+package deviceplugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// allocate 为单个容器分配指定设备
+func allocate(t *testing.T, plugin *PPUDevicePlugin, deviceIDs ...string) *v1beta1.AllocateResponse {
+	t.Helper()
+
+	response, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: deviceIDs}},
+	})
+	if err != nil {
+		t.Fatalf("Allocate %v failed: %v", deviceIDs, err)
+	}
+	return response
+}
+
+// TestPerDeviceAllocationMetrics 测试按设备的分配计数
+func TestPerDeviceAllocationMetrics(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{PerDeviceMetrics: true})
+
+	allocate(t, plugin, "ppu-1")
+
+	if got := testutil.ToFloat64(plugin.metrics.deviceAllocations.WithLabelValues("ppu-1")); got != 1 {
+		t.Errorf("Expected ppu-1 allocation counter to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(plugin.metrics.deviceAllocations.WithLabelValues("ppu-0")); got != 0 {
+		t.Errorf("Expected ppu-0 allocation counter to be 0, got %v", got)
+	}
+	if count, err := testutil.GatherAndCount(plugin.metrics.registry, "ppu_device_allocations_total"); err != nil || count == 0 {
+		t.Errorf("Expected per-device counter to be exported, got count %d, err %v", count, err)
+	}
+}
+
+// TestPerDeviceAllocationMetricsDisabled 测试默认不导出按设备的分配计数
+func TestPerDeviceAllocationMetricsDisabled(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+
+	allocate(t, plugin, "ppu-1")
+
+	if count, err := testutil.GatherAndCount(plugin.metrics.registry, "ppu_device_allocations_total"); err != nil || count != 0 {
+		t.Errorf("Expected per-device counter not to be exported, got count %d, err %v", count, err)
+	}
+}
+
+// scrapeMetric 请求/metrics并解析指定序列的值
+func scrapeMetric(t *testing.T, plugin *PPUDevicePlugin, series string) float64 {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected /metrics status 200, got %d", rec.Code)
+	}
+
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, series+" ") {
+			value, err := strconv.ParseFloat(strings.TrimPrefix(line, series+" "), 64)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", line, err)
+			}
+			return value
+		}
+	}
+	return 0
+}
+
+// TestMetricsEndpoint 测试/metrics导出分配和设备数量指标
+func TestMetricsEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+
+	if got := scrapeMetric(t, plugin, "ppu_total_devices"); got != 4 {
+		t.Errorf("Expected ppu_total_devices 4, got %v", got)
+	}
+
+	before := scrapeMetric(t, plugin, `ppu_allocate_requests_total{outcome="success"}`)
+	allocate(t, plugin, "ppu-0", "ppu-1")
+	after := scrapeMetric(t, plugin, `ppu_allocate_requests_total{outcome="success"}`)
+	if after != before+1 {
+		t.Errorf("Expected allocate counter to increase by 1, went from %v to %v", before, after)
+	}
+	if got := scrapeMetric(t, plugin, "ppu_allocated_devices_total"); got != 2 {
+		t.Errorf("Expected ppu_allocated_devices_total 2, got %v", got)
+	}
+
+	plugin.applyHealthEvent(DeviceHealthEvent{ID: "ppu-3", Health: v1beta1.Unhealthy})
+	if got := scrapeMetric(t, plugin, "ppu_unhealthy_devices"); got != 1 {
+		t.Errorf("Expected ppu_unhealthy_devices 1, got %v", got)
+	}
+}
+
+// TestAllocateDurationAndAllocatedGauge 测试Allocate耗时直方图按结果计数，已分配设备数量随分配和释放变化
+func TestAllocateDurationAndAllocatedGauge(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+
+	allocate(t, plugin, "ppu-0")
+	allocate(t, plugin, "ppu-1", "ppu-2")
+	if _, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	}); err == nil {
+		t.Fatal("Expected allocating ppu-0 twice to fail")
+	}
+
+	if got := testutil.ToFloat64(plugin.metrics.devicesAllocated); got != 3 {
+		t.Errorf("Expected 3 allocated devices, got %v", got)
+	}
+	if got := scrapeMetric(t, plugin, `ppu_allocate_duration_seconds_count{outcome="success"}`); got != 2 {
+		t.Errorf("Expected 2 successful Allocate observations, got %v", got)
+	}
+	if got := scrapeMetric(t, plugin, `ppu_allocate_duration_seconds_count{outcome="error"}`); got != 1 {
+		t.Errorf("Expected 1 failed Allocate observation, got %v", got)
+	}
+
+	plugin.releaseDevices([]string{"ppu-1", "ppu-2"})
+	if got := testutil.ToFloat64(plugin.metrics.devicesAllocated); got != 1 {
+		t.Errorf("Expected 1 allocated device after release, got %v", got)
+	}
+}
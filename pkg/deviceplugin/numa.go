@@ -0,0 +1,66 @@
+package deviceplugin
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// NUMAPreferenceMetadataKey GetPreferredAllocation请求的gRPC元数据中指定首选NUMA节点的键
+const NUMAPreferenceMetadataKey = "ppu-preferred-numa-node"
+
+// preferredNUMANode 返回首选的NUMA节点。优先级：请求元数据中的NUMAPreferenceMetadataKey、
+// Options.PreferredNUMANode、无偏好；元数据中的值无效时忽略并使用插件默认值
+func (p *PPUDevicePlugin) preferredNUMANode(ctx context.Context) (int64, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(NUMAPreferenceMetadataKey); len(values) > 0 {
+			node, err := strconv.ParseInt(values[0], 10, 64)
+			if err == nil && node >= 0 {
+				return node, true
+			}
+			p.log.Warnf("Ignoring invalid NUMA preference %q: must be a non-negative integer", values[0])
+		}
+	}
+	if p.opts.PreferredNUMANode != nil {
+		return *p.opts.PreferredNUMANode, true
+	}
+	return 0, false
+}
+
+// selectOnNUMANode 优先在首选NUMA节点内按分配策略选择设备；该节点的设备不足时先选取其全部设备，
+// 再由分配策略从其他节点补足
+func (p *PPUDevicePlugin) selectOnNUMANode(available, mustInclude []string, size int, node int64) []string {
+	nodes := p.numaNodeSets(available)
+	onNode, others := []string{}, []string{}
+	for _, deviceID := range available {
+		if contains(mustInclude, deviceID) {
+			continue
+		}
+		if containsNode(nodes[deviceID], node) {
+			onNode = append(onNode, deviceID)
+		} else {
+			others = append(others, deviceID)
+		}
+	}
+
+	if len(mustInclude)+len(onNode) >= size {
+		return p.selectPreferred(onNode, mustInclude, size)
+	}
+
+	p.log.Warnf("Preferred NUMA node %d has %d available devices, spilling %d devices to other nodes",
+		node, len(onNode), size-len(mustInclude)-len(onNode))
+	sort.Slice(onNode, func(i, j int) bool { return deviceIDLess(onNode[i], onNode[j]) })
+	return p.selectPreferred(others, append(append([]string{}, mustInclude...), onNode...), size)
+}
+
+// containsNode 判断设备所在的NUMA节点中是否包含node
+func containsNode(nodes []int64, node int64) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package deviceplugin
+
+import (
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// defaultHealthCheckInterval 默认的设备健康检查周期
+	defaultHealthCheckInterval = 30 * time.Second
+	// defaultShutdownTimeout 默认的优雅停止超时时间
+	defaultShutdownTimeout = 10 * time.Second
+	// DefaultMaxDeviceCount 默认的设备数量上限
+	DefaultMaxDeviceCount = 4096
+	// defaultHealthBufferSize 默认的健康状态更新通道容量
+	defaultHealthBufferSize = 64
+	// defaultWatchSendBackoff 默认的ListAndWatch首次重发等待时长
+	defaultWatchSendBackoff = 100 * time.Millisecond
+)
+
+// DeviceOverride 单个设备的初始状态覆盖
+type DeviceOverride struct {
+	// Health 设备的初始健康状态，为空时为Healthy
+	Health string
+	// NUMANode 设备所在的NUMA节点，为空时按轮询方式分布
+	NUMANode *int64
+	// FailureProbability 每次健康检查时设备变为不健康的概率（0-1）
+	FailureProbability float64
+	// Model 设备型号，为空时使用Options.DeviceModel
+	Model string
+	// MemoryMB 设备显存大小（MB），为零时使用Options.DeviceMemoryMB
+	MemoryMB int
+}
+
+// Options PPU设备插件的可选配置，零值表示使用默认行为
+type Options struct {
+	// SocketName 插件socket文件名，为空时由资源名称生成（默认资源名称使用ppu.sock）；同一进程运行多个资源时必须互不相同
+	SocketName string
+	// SocketMode 插件socket文件的权限，为零时使用默认的0600
+	SocketMode os.FileMode
+	// SocketDirMode socket目录不存在时创建目录使用的权限，为零时使用默认的0755
+	SocketDirMode os.FileMode
+	// BuildInfo 构建版本信息，在/info中返回
+	BuildInfo BuildInfo
+	// MetricsAddr 指标与管理HTTP服务的监听地址，为空时不启动
+	MetricsAddr string
+	// EnableAdminAPI 在管理HTTP服务上注册/metrics和/readyz以外的接口（设备视图、预留、重置、维护、健康注入等），
+	// 这些接口没有鉴权，默认关闭
+	EnableAdminAPI bool
+	// PprofAddr net/http/pprof性能分析HTTP服务的监听地址，独立于管理服务，为空时不启动
+	PprofAddr string
+	// StateFile 分配状态的持久化文件路径，为空时不持久化
+	StateFile string
+	// HistorySize 内存中保留的最近分配记录条数，通过/history和/history.csv查询，为零时使用默认的100条
+	HistorySize int
+	// AuditLog 分配审计日志路径，每次成功的Allocate按容器追加JSON行，为空时不记录
+	AuditLog string
+	// PodResourcesSocket PodResources兼容服务的socket路径，为空时不启动
+	PodResourcesSocket string
+	// PerDeviceMetrics 导出按设备标签区分的分配计数指标
+	PerDeviceMetrics bool
+	// PreStartRequired 要求kubelet在容器启动前调用PreStartContainer，并在其中校验请求的设备
+	PreStartRequired bool
+	// DisablePreferredAllocation 不向kubelet声明GetPreferredAllocation，调用时返回Unimplemented
+	DisablePreferredAllocation bool
+	// EmptyOnAllUnhealthy 所有设备均不健康时ListAndWatch上报空列表，而不是全部不健康的列表
+	EmptyOnAllUnhealthy bool
+	// ShuffleDevices 每次ListAndWatch上报时随机打乱设备顺序
+	ShuffleDevices bool
+	// ShuffleSeed 打乱设备顺序所用随机数生成器的种子
+	ShuffleSeed int64
+	// Warmup 启动后拒绝分配请求的预热时长，设备仍正常上报
+	Warmup time.Duration
+	// WatchDebounce ListAndWatch收到健康变化后等待合并后续变化的时长，为零时每次变化立即上报
+	WatchDebounce time.Duration
+	// ListAndWatchMinInterval 同一客户端两次ListAndWatch连接的最小间隔，过快的重连会被延迟，为零时不限制
+	ListAndWatchMinInterval time.Duration
+	// WatchSendRetries ListAndWatch发送失败后的重试次数，重试耗尽后才向kubelet返回错误，为零时不重试
+	WatchSendRetries int
+	// WatchSendBackoff ListAndWatch首次重发前的等待时长，之后每次翻倍，为零时使用默认的100毫秒
+	WatchSendBackoff time.Duration
+	// ListAndWatchInitialDelay ListAndWatch发送首次设备列表前的等待时长，模拟启动缓慢的插件，为零时立即发送
+	ListAndWatchInitialDelay time.Duration
+	// AllocationTTL 模拟设备忙碌时长：Allocate成功后设备在此期间不参与首选分配，到期后由后台回收自动释放，为零时不自动释放
+	AllocationTTL time.Duration
+	// StrictDeviceIDs Allocate收到不符合DeviceIDFormat的设备ID时返回错误，而不是忽略
+	StrictDeviceIDs bool
+	// CacheAllocations 按请求的设备ID缓存容器分配结果，kubelet重试相同请求时返回相同的响应；
+	// 请求中任一设备被释放、健康状态变化或被移除时缓存失效
+	CacheAllocations bool
+	// MaxDevicesPerContainer 单个容器最多可分配的设备数量，超出时Allocate返回InvalidArgument，为零时不限制
+	MaxDevicesPerContainer int
+	// EnvCountKey 分配响应中设备数量环境变量名，为空时使用PPU_DEVICE_COUNT
+	EnvCountKey string
+	// EnvDevicesKey 分配响应中设备列表环境变量名，为空时使用PPU_ALLOCATED_DEVICES
+	EnvDevicesKey string
+	// ExtraEnvs 添加到每个容器分配响应的静态环境变量
+	ExtraEnvs map[string]string
+	// AnnotationPrefix 分配响应注解的命名空间，为空时使用ppu.alibabacloud.com
+	AnnotationPrefix string
+	// DeviceModel 设备型号，以PPU_<n>_MODEL环境变量传入分配到该设备的容器，为空时不注入
+	DeviceModel string
+	// DeviceMemoryMB 设备显存大小（MB），以PPU_<n>_MEMORY_MB环境变量传入容器，为零时不注入
+	DeviceMemoryMB int
+	// DevicePathTemplate 设备节点路径模板（如/dev/ppu%d），以设备序号替换，为空时映射到/dev/null
+	DevicePathTemplate string
+	// EnableCDI 在分配响应中返回CDI设备名称代替传统的设备规格
+	EnableCDI bool
+	// Mounts 添加到每个容器分配响应的挂载
+	Mounts []*v1beta1.Mount
+	// ShmSize 共享内存大小（如64Mi），非空时为每个容器挂载/dev/shm并以shm-size注解传入大小
+	ShmSize string
+	// Hugepages 大页大小列表（如2Mi），每个大小为容器挂载/dev/hugepages-<size>并以hugepages注解传入
+	Hugepages []string
+	// AllocateLatency 每次Allocate的基础模拟延迟
+	AllocateLatency time.Duration
+	// AllocateLatencyJitter 每次Allocate额外增加的随机延迟上限，实际延迟在[0, AllocateLatencyJitter)内均匀分布
+	AllocateLatencyJitter time.Duration
+	// AllocationDelayPerDevice 每个请求设备额外增加的模拟延迟，模拟驱动逐个初始化设备
+	AllocationDelayPerDevice time.Duration
+	// NoRegister 试运行模式：启动gRPC服务但不向kubelet注册，便于测试客户端直接连接插件socket
+	NoRegister bool
+	// Maintenance 以维护模式启动，上报空的设备列表直到调用SetMaintenance(false)
+	Maintenance bool
+	// DrainGrace 停止前排空（上报空设备列表）后等待kubelet感知的时长，为零时不等待
+	DrainGrace time.Duration
+	// UnaryInterceptors 追加到插件gRPC服务器一元调用拦截器链末尾的拦截器，在内置的panic恢复和调试日志之后执行
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	// StreamInterceptors 追加到插件gRPC服务器流式调用拦截器链末尾的拦截器
+	StreamInterceptors []grpc.StreamServerInterceptor
+	// ShutdownTimeout 优雅停止时等待进行中请求完成的最长时间，为零时使用默认的10秒
+	ShutdownTimeout time.Duration
+	// RegisterRetries 注册kubelet失败后的重试次数
+	RegisterRetries int
+	// RegisterBackoff 首次重试前的等待时长，之后每次翻倍
+	RegisterBackoff time.Duration
+	// FirmwareVersions 设备ID到模拟固件版本的映射，未列出的设备版本为空
+	FirmwareVersions map[string]string
+	// FirmwareHomogeneous 首选分配时尽量让容器的设备使用相同固件版本
+	FirmwareHomogeneous bool
+	// HealthCheckInterval 设备健康检查周期，为零时使用默认的30秒
+	HealthCheckInterval time.Duration
+	// HealthBufferSize 健康状态更新通道的容量，通道满时更新被丢弃并计入ppu_health_updates_dropped_total，为零时使用默认的64
+	HealthBufferSize int
+	// HealthSendTimeout 健康通道已满时等待ListAndWatch消费的最长时间，超时后丢弃更新，为零时不等待
+	HealthSendTimeout time.Duration
+	// HealthJitter 健康检查周期的随机抖动比例（0-1），如0.1表示每个周期在±10%内随机变化，为零时不抖动
+	HealthJitter float64
+	// ChaosSeed 健康故障注入所用随机数生成器的种子，为零时使用RNGSeed
+	ChaosSeed int64
+	// RNGSeed 插件随机数生成器（故障注入、延迟抖动等）的种子，为零时使用当前时间
+	RNGSeed int64
+	// Deterministic 确定性模式：所有随机行为（故障注入、健康抖动、延迟抖动、温度、设备打乱）使用同一个以RNGSeed播种的
+	// 随机数生成器，忽略ChaosSeed和ShuffleSeed；相同种子和相同的调用顺序下运行结果完全一致
+	Deterministic bool
+	// AllocateFailureRate 每次Allocate模拟失败并返回Internal错误的概率（0-1）
+	AllocateFailureRate float64
+	// UnhealthyDevices 启动时标记为不健康且不会自动恢复的设备ID
+	UnhealthyDevices []string
+	// DeadDevices 永久损坏的设备ID，始终上报为不健康，任何健康来源都不会恢复，分配时返回错误
+	DeadDevices []string
+	// InitialHealth 启动时的设备健康状态分布，应用在UnhealthyDevices和UnhealthyRatio之后
+	InitialHealth InitialHealth
+	// InitialHealthTransient InitialHealth中不健康的设备可被健康检查恢复，默认保持不健康
+	InitialHealthTransient bool
+	// UnhealthyRatio 启动时随机标记为不健康的设备比例（0-1）
+	UnhealthyRatio float64
+	// HealthCommand 健康检查命令，每个周期以设备ID为$1执行，退出码为0表示健康，为空时不启用
+	HealthCommand string
+	// HealthCommandTimeout 健康检查命令单次执行超时，超时视为不健康，为零时使用默认的5秒
+	HealthCommandTimeout time.Duration
+	// HealthSource 设备健康状态来源，为空时使用按HealthCheckInterval周期恢复设备的模拟来源
+	HealthSource HealthSource
+	// DeviceIDFormat 设备ID格式：index（ppu-0）、uuid，或包含一个%d的自定义模板（如gpu-%d），为空时使用index
+	DeviceIDFormat string
+	// DeviceIDSeed uuid格式下生成设备ID所用的种子，相同种子在重启后生成相同的ID
+	DeviceIDSeed int64
+	// MaxDeviceCount 设备数量上限，启动或热插拔时超出返回错误，为零时使用DefaultMaxDeviceCount
+	MaxDeviceCount int
+	// PartitionsPerDevice 每个物理设备划分的分区数，分区作为独立设备上报（如ppu-0-1），为零时不分区
+	PartitionsPerDevice int
+	// NUMANodes 模拟的NUMA节点数量，设备按轮询方式分布，为零时不上报拓扑信息
+	NUMANodes int
+	// MultiNUMADevices 跨两个NUMA节点的物理设备ID，拓扑信息同时包含所在节点和下一个节点，NUMANodes小于2时忽略
+	MultiNUMADevices []string
+	// PreferredNUMANode 首选分配默认偏向的NUMA节点，该节点设备不足时从其他节点补足；
+	// 请求元数据中的NUMAPreferenceMetadataKey优先于此配置，为空时不偏向任何节点
+	PreferredNUMANode *int64
+	// DeviceOverrides 按设备ID覆盖设备的初始状态
+	DeviceOverrides map[string]DeviceOverride
+	// AllocationStrategy 首选分配策略（packed、spread、numa-packed、interconnect、temperature-aware，
+	// 或通过RegisterStrategy注册的策略名称），为空时使用packed
+	AllocationStrategy string
+	// InterconnectGroups 设备互联组（类似NVLink岛），interconnect策略下首选分配尽量落在同一组内
+	InterconnectGroups [][]string
+	// PowerDomains 供电域分组，每组为一个供电域内的设备ID
+	PowerDomains [][]string
+	// PowerStrategy 按供电域选择首选分配的策略（concentrate或spread），为空时不启用，仅在内置packed策略下生效
+	PowerStrategy string
+}
@@ -0,0 +1,69 @@
+package deviceplugin
+
+import (
+	"sort"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// deviceIDLess 按自然顺序比较设备ID，使ppu-2排在ppu-10之前
+func deviceIDLess(a, b string) bool {
+	for a != "" && b != "" {
+		aChunk, aRest, aNum := nextIDChunk(a)
+		bChunk, bRest, bNum := nextIDChunk(b)
+
+		if aNum && bNum {
+			// 去掉前导零后比较数字长度和大小
+			aTrim, bTrim := trimLeadingZeros(aChunk), trimLeadingZeros(bChunk)
+			if len(aTrim) != len(bTrim) {
+				return len(aTrim) < len(bTrim)
+			}
+			if aTrim != bTrim {
+				return aTrim < bTrim
+			}
+		} else if aChunk != bChunk {
+			return aChunk < bChunk
+		}
+
+		a, b = aRest, bRest
+	}
+	return len(a) < len(b)
+}
+
+// nextIDChunk 切分出开头连续的数字或非数字片段
+func nextIDChunk(s string) (chunk, rest string, numeric bool) {
+	numeric = isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == numeric {
+		i++
+	}
+	return s[:i], s[i:], numeric
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func trimLeadingZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
+
+// sortDevices 按设备ID的自然顺序排序
+func sortDevices(devices []*v1beta1.Device) {
+	sort.Slice(devices, func(i, j int) bool {
+		return deviceIDLess(devices[i].ID, devices[j].ID)
+	})
+}
+
+// shuffleDevices 使用插件的随机数生成器打乱设备顺序
+func (p *PPUDevicePlugin) shuffleDevices(devices []*v1beta1.Device) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.shuffleRand.Shuffle(len(devices), func(i, j int) {
+		devices[i], devices[j] = devices[j], devices[i]
+	})
+}
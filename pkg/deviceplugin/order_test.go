@@ -0,0 +1,74 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+// deviceIDs 返回ListAndWatch上报的设备ID顺序
+func deviceIDs(plugin *PPUDevicePlugin) []string {
+	ids := []string{}
+	for _, device := range plugin.deviceList() {
+		ids = append(ids, device.ID)
+	}
+	return ids
+}
+
+// TestStableDeviceOrder 测试默认按自然顺序上报设备
+func TestStableDeviceOrder(t *testing.T) {
+	plugin := newTestPlugin(t, 12, Options{})
+
+	expected := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5",
+		"ppu-6", "ppu-7", "ppu-8", "ppu-9", "ppu-10", "ppu-11"}
+	for i := 0; i < 3; i++ {
+		if got := deviceIDs(plugin); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected stable order %v, got %v", expected, got)
+		}
+	}
+}
+
+// TestShuffleDevices 测试开启后顺序在每次上报间变化，且相同种子可复现
+func TestShuffleDevices(t *testing.T) {
+	opts := Options{ShuffleDevices: true, ShuffleSeed: 42}
+	first := newTestPlugin(t, 8, opts)
+	second := newTestPlugin(t, 8, opts)
+
+	var sends [][]string
+	for i := 0; i < 5; i++ {
+		order := deviceIDs(first)
+		if replay := deviceIDs(second); !reflect.DeepEqual(order, replay) {
+			t.Fatalf("Send %d not reproducible with the same seed: %v vs %v", i, order, replay)
+		}
+		sends = append(sends, order)
+	}
+
+	varied := false
+	for _, order := range sends[1:] {
+		if !reflect.DeepEqual(order, sends[0]) {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Errorf("Expected device order to vary between sends, got %v", sends)
+	}
+}
+
+// TestDeviceIDLess 测试设备ID自然排序
+func TestDeviceIDLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"ppu-2", "ppu-10", true},
+		{"ppu-10", "ppu-2", false},
+		{"ppu-0-2", "ppu-0-10", true},
+		{"ppu-1", "ppu-1", false},
+		{"gpu-9", "ppu-0", true},
+	}
+
+	for _, tt := range tests {
+		if got := deviceIDLess(tt.a, tt.b); got != tt.less {
+			t.Errorf("deviceIDLess(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.less)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package deviceplugin
+
+import "fmt"
+
+// partitionIDs 返回物理设备的分区ID（如ppu-0-1、ppu-0-2），partitions为零时返回设备自身
+func partitionIDs(deviceID string, partitions int) []string {
+	if partitions <= 0 {
+		return []string{deviceID}
+	}
+
+	ids := make([]string, 0, partitions)
+	for i := 1; i <= partitions; i++ {
+		ids = append(ids, fmt.Sprintf("%s-%d", deviceID, i))
+	}
+	return ids
+}
+
+// parentDevice 返回分区所属的物理设备ID，未分区的设备返回自身
+func (p *PPUDevicePlugin) parentDevice(deviceID string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.parentDeviceLocked(deviceID)
+}
+
+// parentDeviceLocked 同parentDevice，调用方需持有p.mu
+func (p *PPUDevicePlugin) parentDeviceLocked(deviceID string) string {
+	if parentID, ok := p.parents[deviceID]; ok {
+		return parentID
+	}
+	return deviceID
+}
@@ -0,0 +1,58 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestPartitionsPerDevice 测试分区作为独立设备上报、可单独分配，并在拓扑中按所属设备分组
+func TestPartitionsPerDevice(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{PartitionsPerDevice: 4, NUMANodes: 2})
+
+	stream := runListAndWatch(t, plugin)
+	ids := advertisedIDs(stream.next(t))
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+
+	expected := []string{
+		"ppu-0-1", "ppu-0-2", "ppu-0-3", "ppu-0-4",
+		"ppu-1-1", "ppu-1-2", "ppu-1-3", "ppu-1-4",
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected advertised devices %v, got %v", expected, ids)
+	}
+
+	for _, id := range expected {
+		parentID := id[:strings.LastIndex(id, "-")]
+		if got := plugin.parentDevice(id); got != parentID {
+			t.Errorf("Expected parent of %s to be %s, got %s", id, parentID, got)
+		}
+	}
+
+	response := allocate(t, plugin, "ppu-1-3")
+	if got := response.ContainerResponses[0].Envs[DefaultEnvDevicesKey]; got != "ppu-1-3" {
+		t.Errorf("Expected partition ppu-1-3 to be allocated, got %s", got)
+	}
+
+	var b strings.Builder
+	if err := plugin.WriteTopologyDOT(&b); err != nil {
+		t.Fatalf("WriteTopologyDOT failed: %v", err)
+	}
+	dot := b.String()
+	for _, want := range []string{
+		`"ppu-0" [shape=box3d];`,
+		`"ppu-0" -- "ppu-0-1";`,
+		`"ppu-0" -- "ppu-0-4";`,
+		`"ppu-1" -- "ppu-1-2";`,
+		`"numa-0" -- "ppu-0";`,
+		`"numa-1" -- "ppu-1";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %s, got:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, `"ppu-1" -- "ppu-0-`) || strings.Contains(dot, `"numa-0" -- "ppu-0-1"`) {
+		t.Errorf("Expected partitions grouped only under their parent device:\n%s", dot)
+	}
+}
@@ -2,22 +2,36 @@ package deviceplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
 const (
 	// PPU设备插件的Socket名称
 	PPUSocket = "ppu.sock"
+	// PPUResourceName 默认的PPU资源名称，为兼容单资源部署继续使用PPUSocket
+	PPUResourceName = "alibabacloud.com/ppu"
 	// Kubelet设备插件注册Socket
 	KubeletSocket = "kubelet.sock"
+	// DeviceIDPrefix 模拟设备ID的前缀
+	DeviceIDPrefix = "ppu-"
 )
 
 // PPUDevicePlugin 代表PPU设备插件
@@ -26,153 +40,616 @@ type PPUDevicePlugin struct {
 	deviceCount  int
 	socketPath   string
 	socket       string
+	opts         Options
+	// log 带有socket、resource和pid字段的日志记录器，区分同一节点上多个插件实例的日志
+	log *log.Entry
+
+	// stateMu保护插件的启动状态，防止重复启动
+	stateMu sync.Mutex
+	started bool
+	// ready 设备初始化、gRPC服务和注册均成功后为true，停止或恢复失败时为false
+	ready atomic.Bool
+	// draining 排空状态，ListAndWatch上报空的设备列表
+	draining atomic.Bool
+	// maintenance 维护模式，ListAndWatch上报空的设备列表但插件保持注册
+	maintenance atomic.Bool
+
+	// healthMu保护健康检查的运行状态
+	healthMu     sync.Mutex
+	healthCtx    context.Context
+	healthCancel context.CancelFunc
+	healthPaused bool
+
+	// mu保护devices及各设备的运行时状态
+	mu          sync.RWMutex
+	utilization map[string]float64
+	temperature map[string]float64
+	attributes  map[string]DeviceAttributes
+	metrics     *metrics
+	warmupUntil time.Time
+	startedAt   time.Time
+	shuffleRand *rand.Rand
+	rng         *rand.Rand
+
+	// stickyUnhealthy 注入的不健康设备，健康检查不会将其恢复
+	stickyUnhealthy map[string]bool
+	// permanentlyDead 永久损坏的设备，任何健康来源都不会将其恢复，且分配会被拒绝
+	permanentlyDead map[string]bool
+	// reserved 预留的设备，不上报给kubelet且拒绝分配
+	reserved map[string]bool
+	// interconnect 设备到互联组序号的映射，由Options.InterconnectGroups生成，创建后只读
+	interconnect map[string]int64
+	// parents 分区到所属物理设备的映射（partitionID -> deviceID），未启用分区时为空
+	parents map[string]string
+	// indexes 物理设备ID到设备序号的映射，设备ID格式不包含序号（如uuid）时用于生成设备路径
+	indexes map[string]int
+
+	// allocated 记录已分配设备及其分配对象（deviceID -> owner）
+	allocated         map[string]string
+	allocationSeq     uint64
+	allocationsServed uint64
+	allocationHooks   []AllocationHook
+	history           *allocationHistory
+	// allocationCache 按请求签名缓存的容器分配结果，仅在Options.CacheAllocations启用时使用
+	allocationCache map[string]*cachedAllocation
+	// resetGeneration 每次Reset递增，Allocate提交时发现变化则放弃提交
+	resetGeneration uint64
+	// busyUntil 已分配设备的忙碌截止时间，到期后自动释放，仅在Options.AllocationTTL启用时使用
+	busyUntil map[string]time.Time
+	// deviceSource 嵌入方设置的设备列表来源，为空时上报devices
+	deviceSource DeviceSource
+
+	// persistMu 串行化状态文件的写入
+	persistMu sync.Mutex
+	// auditMu 串行化审计日志的追加
+	auditMu sync.Mutex
+
+	// lastListAndWatch 记录各客户端最近一次被接受的ListAndWatch连接时间
+	lastListAndWatch map[string]time.Time
+	// listAndWatchStreams 当前活跃的ListAndWatch流数量，受mu保护
+	listAndWatchStreams int
+
+	server      *grpc.Server
+	adminServer *http.Server
+	// pprofServer 性能分析HTTP服务，未配置Options.PprofAddr时为空
+	pprofServer *http.Server
+	// registerServices 向gRPC服务器注册服务，为空时注册设备插件服务，测试中可替换以模拟服务缺失
+	registerServices func(server *grpc.Server)
+	// podResourcesServer PodResources兼容服务，未配置socket时为空
+	podResourcesServer *grpc.Server
+	devices            map[string]*v1beta1.Device
+	health             chan *v1beta1.Device
+	// events 设备健康状态变化事件总线，与供ListAndWatch使用的health通道相互独立
+	events *eventBus
+	// listChanged 设备列表变化（如预留）时通知ListAndWatch重新上报
+	listChanged chan struct{}
+	stop        chan struct{}
+}
+
+// SocketNameForResource 根据资源名称生成插件socket名称，如alibabacloud.com/ppu-shared对应alibabacloud.com_ppu-shared.sock，
+// 字母、数字、点、横线和下划线以外的字符替换为下划线
+func SocketNameForResource(resourceName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, resourceName)
+	return name + ".sock"
+}
+
+// socketName 返回插件socket名称：优先使用Options.SocketName，默认资源名称使用PPUSocket，其余按资源名称生成
+func socketName(resourceName string, opts Options) string {
+	if opts.SocketName != "" {
+		return opts.SocketName
+	}
+	if resourceName == PPUResourceName {
+		return PPUSocket
+	}
+	return SocketNameForResource(resourceName)
+}
 
-	server  *grpc.Server
-	devices map[string]*v1beta1.Device
-	health  chan *v1beta1.Device
-	stop    chan struct{}
+// Socket 返回插件socket的完整路径
+func (p *PPUDevicePlugin) Socket() string {
+	return p.socket
 }
 
 // NewPPUDevicePlugin 创建新的PPU设备插件实例
 func NewPPUDevicePlugin(resourceName string, deviceCount int, socketPath string) *PPUDevicePlugin {
-	log.Debugf("Creating new PPU device plugin with resource name: %s, device count: %d", resourceName, deviceCount)
+	return NewPPUDevicePluginWithOptions(resourceName, deviceCount, socketPath, Options{})
+}
+
+// NewPPUDevicePluginWithOptions 使用可选配置创建PPU设备插件实例
+func NewPPUDevicePluginWithOptions(resourceName string, deviceCount int, socketPath string, opts Options) *PPUDevicePlugin {
+	socket := filepath.Join(socketPath, socketName(resourceName, opts))
+	logger := log.WithFields(log.Fields{
+		"socket":   socket,
+		"resource": resourceName,
+		"pid":      os.Getpid(),
+	})
+	logger.Debugf("Creating new PPU device plugin with resource name: %s, device count: %d", resourceName, deviceCount)
+
+	// 确定性模式下打乱设备顺序与其他随机行为共用同一个随机数生成器
+	rng := rand.New(rand.NewSource(chaosSeed(opts)))
+	shuffleRand := rand.New(rand.NewSource(opts.ShuffleSeed))
+	if opts.Deterministic {
+		shuffleRand = rng
+	}
 
-	return &PPUDevicePlugin{
-		resourceName: resourceName,
-		deviceCount:  deviceCount,
-		socketPath:   socketPath,
-		socket:       filepath.Join(socketPath, PPUSocket),
-		devices:      make(map[string]*v1beta1.Device),
-		health:       make(chan *v1beta1.Device),
-		stop:         make(chan struct{}),
+	p := &PPUDevicePlugin{
+		resourceName:     resourceName,
+		deviceCount:      deviceCount,
+		socketPath:       socketPath,
+		socket:           socket,
+		log:              logger,
+		opts:             opts,
+		utilization:      make(map[string]float64),
+		temperature:      make(map[string]float64),
+		attributes:       make(map[string]DeviceAttributes),
+		allocated:        make(map[string]string),
+		busyUntil:        make(map[string]time.Time),
+		allocationCache:  make(map[string]*cachedAllocation),
+		reserved:         make(map[string]bool),
+		permanentlyDead:  make(map[string]bool),
+		parents:          make(map[string]string),
+		indexes:          make(map[string]int),
+		interconnect:     interconnectIndex(opts.InterconnectGroups),
+		lastListAndWatch: make(map[string]time.Time),
+		history:          newAllocationHistory(opts.HistorySize),
+		shuffleRand:      shuffleRand,
+		rng:              rng,
+		metrics:          newMetrics(opts),
+		devices:          make(map[string]*v1beta1.Device),
+		health:           make(chan *v1beta1.Device, healthBufferSize(opts)),
+		listChanged:      make(chan struct{}, 1),
+		events:           newEventBus(logger),
+		stop:             make(chan struct{}),
 	}
+	p.maintenance.Store(opts.Maintenance)
+	return p
 }
 
 // Start 启动设备插件
 func (p *PPUDevicePlugin) Start() error {
-	log.Info("Starting PPU device plugin")
+	return p.StartContext(context.Background())
+}
+
+// StartContext 启动设备插件，ctx取消时中止注册重试并返回
+func (p *PPUDevicePlugin) StartContext(ctx context.Context) error {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	if p.started {
+		return fmt.Errorf("device plugin already started on socket %s", p.socket)
+	}
+
+	p.log.Info("Starting PPU device plugin")
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	// 提前检查socket目录可写，避免初始化到一半才失败
+	if err := checkSocketPath(p.socketPath, socketDirMode(p.opts)); err != nil {
+		return err
+	}
 
 	// 初始化模拟设备
 	if err := p.initDevices(); err != nil {
-		return fmt.Errorf("failed to initialize devices: %v", err)
+		return err
 	}
 
+	// 恢复持久化的分配状态
+	p.loadState()
+
 	// 启动gRPC服务器
 	if err := p.serve(); err != nil {
-		return fmt.Errorf("failed to start gRPC server: %v", err)
+		p.stopServer()
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+
+	// 自检：确认设备插件服务已注册并能响应请求后再注册到kubelet
+	if err := p.selfTest(ctx); err != nil {
+		p.stopServer()
+		return err
+	}
+
+	// 注册到kubelet，试运行模式下跳过
+	if p.opts.NoRegister {
+		p.log.Warnf("Skipping kubelet registration (no-register mode); clients can connect to %s directly", p.socket)
+	} else if err := p.register(ctx); err != nil {
+		p.stopServer()
+		return err
+	}
+
+	// 启动管理HTTP服务
+	if err := p.startAdminServer(); err != nil {
+		p.stopServer()
+		return fmt.Errorf("failed to start admin server: %v", err)
+	}
+
+	// 启动性能分析服务
+	if err := p.startPprofServer(); err != nil {
+		p.stopServer()
+		p.stopAdminServer()
+		return fmt.Errorf("failed to start pprof server: %v", err)
+	}
+
+	// 启动PodResources兼容服务
+	if err := p.startPodResourcesServer(); err != nil {
+		p.stopServer()
+		p.stopAdminServer()
+		p.stopPprofServer()
+		return fmt.Errorf("failed to start pod resources server: %v", err)
 	}
 
-	// 注册到kubelet
-	if err := p.register(); err != nil {
-		return fmt.Errorf("failed to register with kubelet: %v", err)
+	// 监听kubelet重启及插件socket被删除，试运行模式下只重建socket而不重新注册
+	if err := p.startKubeletWatcher(); err != nil {
+		p.stopServer()
+		p.stopAdminServer()
+		p.stopPprofServer()
+		p.stopPodResourcesServer()
+		return fmt.Errorf("failed to start kubelet watcher: %v", err)
 	}
 
-	log.Info("PPU device plugin started successfully")
+	// 配置了分配TTL时定期回收过期的分配
+	p.startAllocationReaper()
+
+	p.started = true
+	p.mu.Lock()
+	p.startedAt = time.Now()
+	p.mu.Unlock()
+	p.beginWarmup()
+	p.ready.Store(true)
+	p.log.Info("PPU device plugin started successfully")
 	return nil
 }
 
-// Stop 停止设备插件
+// Stop 停止设备插件，等待进行中的请求完成，最长等待ShutdownTimeout
 func (p *PPUDevicePlugin) Stop() {
-	log.Info("Stopping PPU device plugin")
+	p.StopContext(context.Background())
+}
+
+// StopContext 停止设备插件，等待进行中的请求完成直到ctx取消或超过ShutdownTimeout，超时后强制停止
+func (p *PPUDevicePlugin) StopContext(ctx context.Context) {
+	p.log.Info("Stopping PPU device plugin")
+	p.ready.Store(false)
 
+	// 先通知ListAndWatch和健康检查退出，否则优雅停止会一直等待长连接
 	close(p.stop)
 
+	p.gracefulStopServer(ctx)
+	p.stopAdminServer()
+	p.stopPprofServer()
+	p.stopPodResourcesServer()
+
+	p.logShutdownReport()
+	p.log.Info("PPU device plugin stopped")
+}
+
+// stopServer 停止gRPC服务器并清理socket文件
+func (p *PPUDevicePlugin) stopServer() {
 	if p.server != nil {
 		p.server.Stop()
+		p.server = nil
 	}
 
 	// 清理socket文件
 	if err := os.Remove(p.socket); err != nil && !os.IsNotExist(err) {
-		log.Warnf("Failed to remove socket file: %v", err)
+		p.log.Warnf("Failed to remove socket file: %v", err)
+	}
+}
+
+// shutdownTimeout 返回优雅停止的最长等待时间
+func (p *PPUDevicePlugin) shutdownTimeout() time.Duration {
+	if p.opts.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return p.opts.ShutdownTimeout
+}
+
+// gracefulStopServer 优雅停止gRPC服务器，超时后回退为强制停止
+func (p *PPUDevicePlugin) gracefulStopServer(ctx context.Context) {
+	if server := p.server; server != nil {
+		ctx, cancel := context.WithTimeout(ctx, p.shutdownTimeout())
+		defer cancel()
+
+		done := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			p.log.Debug("gRPC server drained in-flight requests")
+		case <-ctx.Done():
+			p.log.Warnf("Graceful shutdown did not finish in time (%v), forcing stop", ctx.Err())
+			server.Stop()
+			<-done
+		}
 	}
 
-	log.Info("PPU device plugin stopped")
+	p.stopServer()
 }
 
 // initDevices 初始化模拟PPU设备
 func (p *PPUDevicePlugin) initDevices() error {
-	log.Infof("Initializing %d PPU devices", p.deviceCount)
+	p.log.Infof("Initializing %d PPU devices", p.deviceCount)
+	if p.deviceCount < 0 {
+		return fmt.Errorf("%w: invalid device count %d", ErrDeviceInit, p.deviceCount)
+	}
 
+	deviceIDs := make([]string, 0, p.deviceCount)
 	for i := 0; i < p.deviceCount; i++ {
-		deviceID := fmt.Sprintf("ppu-%d", i)
+		deviceIDs = append(deviceIDs, p.addDevice(i)...)
+	}
+
+	for deviceID := range p.opts.DeviceOverrides {
+		if _, ok := p.devices[deviceID]; !ok {
+			p.log.Warnf("Override for device %s does not match any device, ignoring", deviceID)
+		}
+	}
+	for _, deviceID := range p.opts.MultiNUMADevices {
+		if _, ok := p.indexes[deviceID]; !ok {
+			p.log.Warnf("Multi-NUMA device %s does not match any device, ignoring", deviceID)
+		}
+	}
+
+	// 注入配置的不健康设备，ListAndWatch首次上报即包含这些状态
+	p.injectUnhealthyDevices(deviceIDs)
+	p.applyInitialHealth(deviceIDs)
+	p.markDeadDevices(deviceIDs)
+
+	p.mu.Lock()
+	count := len(p.devices)
+	p.updateDeviceGaugesLocked()
+	p.mu.Unlock()
+
+	p.log.Infof("Successfully initialized %d PPU devices", count)
+	return nil
+}
+
+// addDevice 创建序号为index的物理设备（启用分区时为其全部分区），返回新增的设备ID
+func (p *PPUDevicePlugin) addDevice(index int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.addDeviceLocked(index)
+}
+
+// addDeviceLocked 创建序号为index的物理设备及其分区，调用方需持有p.mu
+func (p *PPUDevicePlugin) addDeviceLocked(index int) []string {
+	parentID := p.deviceIDForIndex(index)
+	deviceIDs := partitionIDs(parentID, p.opts.PartitionsPerDevice)
+	for _, deviceID := range deviceIDs {
 		device := &v1beta1.Device{
 			ID:     deviceID,
 			Health: v1beta1.Healthy,
 		}
+		if p.opts.NUMANodes > 0 {
+			// 按轮询方式将物理设备分布到各NUMA节点，分区与所属设备位于同一节点
+			nodes := []*v1beta1.NUMANode{{ID: int64(index % p.opts.NUMANodes)}}
+			// 跨NUMA的设备同时属于下一个节点
+			if p.opts.NUMANodes > 1 && contains(p.opts.MultiNUMADevices, parentID) {
+				nodes = append(nodes, &v1beta1.NUMANode{ID: int64((index + 1) % p.opts.NUMANodes)})
+			}
+			device.Topology = &v1beta1.TopologyInfo{Nodes: nodes}
+		}
+		p.applyDeviceOverride(device)
 
 		p.devices[deviceID] = device
-		log.Debugf("Initialized PPU device: %s", deviceID)
+		if deviceID != parentID {
+			p.parents[deviceID] = parentID
+		}
+		p.indexes[parentID] = index
+		p.setUtilizationLocked(deviceID, idleUtilization)
+		p.initTemperatureLocked(deviceID)
+		p.attributes[deviceID] = p.deviceAttributes(deviceID)
+		p.log.Debugf("Initialized PPU device: %s", deviceID)
+	}
+	return deviceIDs
+}
+
+// applyDeviceOverride 应用配置中针对单个设备的覆盖
+func (p *PPUDevicePlugin) applyDeviceOverride(device *v1beta1.Device) {
+	override, ok := p.opts.DeviceOverrides[device.ID]
+	if !ok {
+		return
+	}
+
+	if override.Health != "" {
+		device.Health = override.Health
+	}
+	if override.NUMANode != nil {
+		device.Topology = &v1beta1.TopologyInfo{
+			Nodes: []*v1beta1.NUMANode{{ID: *override.NUMANode}},
+		}
+	}
+	p.log.Debugf("Applied override to device %s: %+v", device.ID, override)
+}
+
+// checkSocketPath 检查socket目录存在（或可以按mode创建）且可写
+func checkSocketPath(dir string, mode os.FileMode) error {
+	if err := os.MkdirAll(dir, mode); err != nil {
+		return fmt.Errorf("socket path %s not writable: %v", dir, pathErrorCause(err))
 	}
 
-	log.Infof("Successfully initialized %d PPU devices", len(p.devices))
+	probe, err := os.CreateTemp(dir, ".ppu-preflight-*")
+	if err != nil {
+		return fmt.Errorf("socket path %s not writable: %v", dir, pathErrorCause(err))
+	}
+	probe.Close()
+	os.Remove(probe.Name())
 	return nil
 }
 
+// pathErrorCause 去掉*os.PathError中的操作和路径，只保留底层原因
+func pathErrorCause(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
+
 // serve 启动gRPC服务器
 func (p *PPUDevicePlugin) serve() error {
-	log.Debugf("Starting gRPC server on socket: %s", p.socket)
+	p.log.Debugf("Starting gRPC server on socket: %s", p.socket)
 
 	// 确保socket目录存在
-	if err := os.MkdirAll(filepath.Dir(p.socket), 0755); err != nil {
-		return fmt.Errorf("failed to create socket directory: %v", err)
+	if err := os.MkdirAll(filepath.Dir(p.socket), socketDirMode(p.opts)); err != nil {
+		return fmt.Errorf("%w %s: failed to create socket directory: %v", ErrSocketListen, p.socket, err)
 	}
 
 	// 删除已存在的socket文件
 	if err := os.Remove(p.socket); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove existing socket: %v", err)
+		return fmt.Errorf("%w %s: failed to remove existing socket: %v", ErrSocketListen, p.socket, err)
 	}
 
 	// 创建Unix socket监听器
 	listener, err := net.Listen("unix", p.socket)
 	if err != nil {
-		return fmt.Errorf("failed to listen on socket %s: %v", p.socket, err)
+		return fmt.Errorf("%w %s: %v", ErrSocketListen, p.socket, err)
+	}
+
+	// socket文件默认继承umask权限，显式收紧为配置的权限
+	if err := os.Chmod(p.socket, socketMode(p.opts)); err != nil {
+		listener.Close()
+		return fmt.Errorf("%w %s: failed to set socket mode: %v", ErrSocketListen, p.socket, err)
 	}
 
 	// 创建gRPC服务器
-	p.server = grpc.NewServer([]grpc.ServerOption{}...)
-	v1beta1.RegisterDevicePluginServer(p.server, p)
+	server := grpc.NewServer(p.serverOptions()...)
+	if p.registerServices != nil {
+		p.registerServices(server)
+	} else {
+		v1beta1.RegisterDevicePluginServer(server, p)
+	}
+	p.server = server
+
+	// 注册gRPC健康检查服务，设备已初始化因此直接标记为SERVING，插件停止时切换为NOT_SERVING
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() {
+		<-p.stop
+		healthServer.Shutdown()
+	}()
 
 	// 在后台启动服务器
 	go func() {
-		log.Debugf("gRPC server listening on socket: %s", p.socket)
-		if err := p.server.Serve(listener); err != nil {
-			log.Errorf("gRPC server failed: %v", err)
+		p.log.Debugf("gRPC server listening on socket: %s", p.socket)
+		if err := server.Serve(listener); err != nil {
+			p.log.Errorf("gRPC server failed: %v", err)
 		}
 	}()
 
 	// 等待服务器启动
-	conn, err := p.dial(p.socket, 5*time.Second)
+	conn, err := p.dial(context.Background(), p.socket, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server: %v", err)
 	}
 	conn.Close()
 
-	log.Info("gRPC server started successfully")
+	p.log.Info("gRPC server started successfully")
+	return nil
+}
+
+// selfTest 通过插件socket调用GetDevicePluginOptions，确认gRPC服务不仅在监听而且能处理设备插件请求
+func (p *PPUDevicePlugin) selfTest(ctx context.Context) error {
+	conn, err := p.dial(ctx, p.socket, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("self-test failed to connect to %s: %v", p.socket, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := v1beta1.NewDevicePluginClient(conn).GetDevicePluginOptions(ctx, &v1beta1.Empty{}); err != nil {
+		return fmt.Errorf("self-test GetDevicePluginOptions on %s failed: %v", p.socket, err)
+	}
+
+	p.log.Debug("Device plugin self-test passed")
 	return nil
 }
 
-// dial 连接到Unix socket
-func (p *PPUDevicePlugin) dial(unixSocketPath string, timeout time.Duration) (*grpc.ClientConn, error) {
-	c, err := grpc.Dial(unixSocketPath, grpc.WithInsecure(), grpc.WithBlock(),
-		grpc.WithTimeout(timeout),
-		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
-			return net.DialTimeout("unix", addr, timeout)
+// dial 连接到Unix socket，阻塞直到连接就绪或超时
+func (p *PPUDevicePlugin) dial(ctx context.Context, unixSocketPath string, timeout time.Duration) (*grpc.ClientConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c, err := grpc.NewClient("passthrough:///"+unixSocketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
 		}),
 	)
-
 	if err != nil {
 		return nil, err
 	}
 
+	if err := waitForReady(ctx, c); err != nil {
+		c.Close()
+		return nil, err
+	}
 	return c, nil
 }
 
-// register 向kubelet注册设备插件
-func (p *PPUDevicePlugin) register() error {
-	log.Info("Registering PPU device plugin with kubelet")
+// waitForReady 主动建立连接并等待其进入Ready状态，ctx结束时返回错误
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection not ready (last state %s): %w", state, ctx.Err())
+		}
+	}
+}
+
+// register 向kubelet注册设备插件，失败时按指数退避重试，ctx取消时立即返回
+func (p *PPUDevicePlugin) register(ctx context.Context) error {
+	backoff := p.opts.RegisterBackoff
+	for attempt := 0; ; attempt++ {
+		err := p.registerOnce(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= p.opts.RegisterRetries {
+			return fmt.Errorf("%w after %d attempts: %w", ErrRegistration, attempt+1, err)
+		}
+
+		p.log.Warnf("Registration attempt %d failed: %v, retrying in %s", attempt+1, err, backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: aborted after %d attempts: %w", ErrRegistration, attempt+1, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// registerOnce 向kubelet发送一次注册请求
+func (p *PPUDevicePlugin) registerOnce(ctx context.Context) error {
+	p.log.Info("Registering PPU device plugin with kubelet")
 
 	kubeletSocket := filepath.Join(p.socketPath, KubeletSocket)
-	conn, err := p.dial(kubeletSocket, 5*time.Second)
+	if _, err := os.Stat(kubeletSocket); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w at %s; is this running inside a node with kubelet?", ErrKubeletSocketNotFound, kubeletSocket)
+		}
+		return fmt.Errorf("failed to stat kubelet socket %s: %v", kubeletSocket, err)
+	}
+
+	conn, err := p.dial(ctx, kubeletSocket, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("failed to connect to kubelet: %v", err)
 	}
@@ -182,13 +659,13 @@ func (p *PPUDevicePlugin) register() error {
 
 	request := &v1beta1.RegisterRequest{
 		Version:      v1beta1.Version,
-		Endpoint:     PPUSocket,
+		Endpoint:     filepath.Base(p.socket),
 		ResourceName: p.resourceName,
 	}
 
-	log.Debugf("Sending registration request: %+v", request)
+	p.log.Debugf("Sending registration request: %+v", request)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	_, err = client.Register(ctx, request)
@@ -196,11 +673,42 @@ func (p *PPUDevicePlugin) register() error {
 		return fmt.Errorf("failed to register device plugin: %v", err)
 	}
 
-	log.Infof("Successfully registered PPU device plugin with resource name: %s", p.resourceName)
+	p.log.Infof("Successfully registered PPU device plugin with resource name: %s", p.resourceName)
 	return nil
 }
 
+// beginWarmup 开始预热期，预热期内拒绝分配请求
+func (p *PPUDevicePlugin) beginWarmup() {
+	if p.opts.Warmup <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	p.warmupUntil = time.Now().Add(p.opts.Warmup)
+	p.mu.Unlock()
+
+	p.log.Infof("Warming up for %s, allocations will be rejected until then", p.opts.Warmup)
+}
+
+// warmingUp 返回插件是否仍处于预热期
+func (p *PPUDevicePlugin) warmingUp() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return time.Now().Before(p.warmupUntil)
+}
+
+// Ready 返回插件是否已就绪（启动成功且完成预热），可以接受分配请求
+func (p *PPUDevicePlugin) Ready() bool {
+	return p.ready.Load() && !p.warmingUp()
+}
+
 // StartHealthCheck 启动设备健康检查
 func (p *PPUDevicePlugin) StartHealthCheck() {
-	p.startHealthCheck()
+	p.startHealthCheck(context.Background())
+}
+
+// StartHealthCheckContext 启动设备健康检查，ctx取消或插件停止时退出
+func (p *PPUDevicePlugin) StartHealthCheckContext(ctx context.Context) {
+	p.startHealthCheck(ctx)
 }
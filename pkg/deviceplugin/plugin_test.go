@@ -2,12 +2,23 @@ package deviceplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/wangmin362/ppu-device-plugin/pkg/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
@@ -30,10 +41,17 @@ func TestPPUDevicePlugin(t *testing.T) {
 
 	// 测试设备初始化
 	t.Run("DeviceInitialization", func(t *testing.T) {
-		// 这里我们需要访问plugin的私有字段，但为了测试简化，我们假设初始化成功
 		if plugin == nil {
 			t.Fatal("Plugin creation failed")
 		}
+
+		if err := plugin.initDevices(); err != nil {
+			t.Fatalf("initDevices failed: %v", err)
+		}
+
+		if len(plugin.devices) != deviceCount {
+			t.Errorf("Expected %d devices, got %d", deviceCount, len(plugin.devices))
+		}
 	})
 
 	// 测试GetDevicePluginOptions
@@ -166,6 +184,9 @@ func TestHelperFunctions(t *testing.T) {
 // BenchmarkDeviceAllocation 性能测试
 func BenchmarkDeviceAllocation(b *testing.B) {
 	plugin := NewPPUDevicePlugin("test.com/ppu", 16, "/tmp")
+	if err := plugin.initDevices(); err != nil {
+		b.Fatalf("initDevices failed: %v", err)
+	}
 
 	ctx := context.Background()
 	request := &v1beta1.AllocateRequest{
@@ -182,13 +203,22 @@ func BenchmarkDeviceAllocation(b *testing.B) {
 		if err != nil {
 			b.Fatalf("Allocate failed: %v", err)
 		}
+		plugin.releaseDevices(request.ContainerRequests[0].DevicesIDs)
 	}
 }
 
-// ExampleUsage 使用示例
-func ExampleUsage() {
+// ExamplePPUDevicePlugin 使用示例
+func ExamplePPUDevicePlugin() {
+	// 示例中没有kubelet，使用临时目录并以试运行模式启动
+	socketPath, err := os.MkdirTemp("", "ppu-example-")
+	if err != nil {
+		fmt.Printf("Failed to create socket path: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(socketPath)
+
 	// 创建设备插件
-	plugin := NewPPUDevicePlugin("alibabacloud.com/ppu", 16, "/var/lib/kubelet/device-plugins/")
+	plugin := NewPPUDevicePluginWithOptions("alibabacloud.com/ppu", 16, socketPath, Options{NoRegister: true})
 
 	// 启动设备插件
 	if err := plugin.Start(); err != nil {
@@ -208,3 +238,675 @@ func ExampleUsage() {
 	fmt.Println("Plugin started and stopped successfully")
 	// Output: Plugin started and stopped successfully
 }
+
+// startFakeKubelet 在指定目录启动模拟的kubelet注册服务，测试结束时停止
+func startFakeKubelet(t *testing.T, dir string) *testutil.FakeKubelet {
+	t.Helper()
+
+	kubelet, err := testutil.NewFakeKubelet(dir)
+	if err != nil {
+		t.Fatalf("Failed to start fake kubelet: %v", err)
+	}
+	t.Cleanup(kubelet.Stop)
+	return kubelet
+}
+
+// TestSocketNameFromResource 测试不同资源名称的插件使用不同的socket，默认资源名称保持使用ppu.sock
+func TestSocketNameFromResource(t *testing.T) {
+	tmpDir := t.TempDir()
+	ppu := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+	shared := NewPPUDevicePlugin("test.com/ppu-shared", 2, tmpDir)
+
+	if ppu.Socket() == shared.Socket() {
+		t.Fatalf("Expected distinct sockets, both use %s", ppu.Socket())
+	}
+	if expected := filepath.Join(tmpDir, "test.com_ppu-shared.sock"); shared.Socket() != expected {
+		t.Errorf("Expected socket %s, got %s", expected, shared.Socket())
+	}
+	if socket := NewPPUDevicePlugin(PPUResourceName, 1, tmpDir).Socket(); filepath.Base(socket) != PPUSocket {
+		t.Errorf("Expected default resource to use %s, got %s", PPUSocket, socket)
+	}
+}
+
+// TestLogFields 测试插件的日志带有socket、resource和pid字段
+func TestLogFields(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	plugin := newTestPlugin(t, 1, Options{})
+	plugin.SetMaintenance(true)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Panic"}
+	panicking := func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") }
+	if _, err := plugin.recoverUnary(context.Background(), nil, info, panicking); status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal error from recovered panic, got %v", err)
+	}
+
+	expected := log.Fields{"socket": plugin.Socket(), "resource": "test.com/ppu", "pid": os.Getpid()}
+	for _, message := range []string{"Entering maintenance mode", "Recovered from panic in /test/Panic"} {
+		var entry *log.Entry
+		for _, e := range hook.AllEntries() {
+			if strings.Contains(e.Message, message) {
+				entry = e
+			}
+		}
+		if entry == nil {
+			t.Fatalf("Expected a log entry containing %q", message)
+		}
+		for key, value := range expected {
+			if entry.Data[key] != value {
+				t.Errorf("Expected field %s=%v, got %v in %q", key, value, entry.Data[key], entry.Message)
+			}
+		}
+	}
+}
+
+// TestRegisterSuccess 测试注册请求携带正确的资源名称、端点和API版本
+func TestRegisterSuccess(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{SocketName: "custom.sock"})
+	if err := plugin.register(context.Background()); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	select {
+	case request := <-kubelet.Requests():
+		if request.ResourceName != "test.com/ppu" {
+			t.Errorf("Expected resource test.com/ppu, got %s", request.ResourceName)
+		}
+		if request.Endpoint != "custom.sock" {
+			t.Errorf("Expected endpoint custom.sock, got %s", request.Endpoint)
+		}
+		if request.Version != v1beta1.Version {
+			t.Errorf("Expected version %s, got %s", v1beta1.Version, request.Version)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for registration")
+	}
+
+	if got := len(kubelet.Received()); got != 1 {
+		t.Errorf("Expected exactly 1 registration, got %d", got)
+	}
+}
+
+// TestRegisterRejected 测试kubelet拒绝注册时返回错误
+func TestRegisterRejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+	kubelet.SetError(status.Error(codes.InvalidArgument, "unsupported version"))
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+	err := plugin.register(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unsupported version") {
+		t.Fatalf("Expected the kubelet error to be returned, got %v", err)
+	}
+	if got := len(kubelet.Received()); got != 0 {
+		t.Errorf("Expected no accepted registrations, got %d", got)
+	}
+}
+
+// TestStartTwice 测试重复调用Start返回错误且不会重复监听
+func TestStartTwice(t *testing.T) {
+	tmpDir := t.TempDir()
+	startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("First Start failed: %v", err)
+	}
+	defer plugin.Stop()
+
+	server := plugin.server
+
+	if err := plugin.Start(); err == nil {
+		t.Fatal("Expected second Start to return an error")
+	}
+
+	if plugin.server != server {
+		t.Error("Second Start replaced the running gRPC server")
+	}
+
+	conn, err := plugin.dial(context.Background(), plugin.socket, time.Second)
+	if err != nil {
+		t.Fatalf("Original socket no longer dialable after second Start: %v", err)
+	}
+	conn.Close()
+}
+
+// TestStartSelfTestFails 测试设备插件服务未注册时Start在向kubelet注册前失败
+func TestStartSelfTestFails(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+	plugin.registerServices = func(server *grpc.Server) {}
+
+	err := plugin.Start()
+	if err == nil {
+		plugin.Stop()
+		t.Fatal("Expected Start to fail when the device plugin service is not registered")
+	}
+	if !strings.Contains(err.Error(), "self-test") || !strings.Contains(err.Error(), "Unimplemented") {
+		t.Errorf("Expected a descriptive self-test error, got %v", err)
+	}
+	if attempts := kubelet.Attempts(); attempts != 0 {
+		t.Errorf("Expected no registration attempts after a failed self-test, got %d", attempts)
+	}
+}
+
+// TestDial 测试dial在socket可用时返回就绪的连接，socket不存在时在超时后返回错误
+func TestDial(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+	plugin := NewPPUDevicePlugin("test.com/ppu", 1, tmpDir)
+
+	conn, err := plugin.dial(context.Background(), kubelet.Socket(), time.Second)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Errorf("Expected a ready connection, got %s", state)
+	}
+	_, err = v1beta1.NewRegistrationClient(conn).Register(context.Background(), &v1beta1.RegisterRequest{
+		Version:      v1beta1.Version,
+		Endpoint:     PPUSocket,
+		ResourceName: "test.com/ppu",
+	})
+	conn.Close()
+	if err != nil {
+		t.Errorf("Register over the dialed connection failed: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := plugin.dial(context.Background(), filepath.Join(tmpDir, "missing.sock"), 100*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded dialing a missing socket, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected dial to give up after the timeout, took %s", elapsed)
+	}
+}
+
+// TestStartNoRegister 测试试运行模式下无需kubelet即可启动并通过socket调用RPC
+func TestStartNoRegister(t *testing.T) {
+	tmpDir := t.TempDir()
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{NoRegister: true, PreStartRequired: true})
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start in no-register mode failed: %v", err)
+	}
+	defer plugin.Stop()
+
+	conn, err := plugin.dial(context.Background(), filepath.Join(tmpDir, SocketNameForResource("test.com/ppu")), time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to plugin socket: %v", err)
+	}
+	defer conn.Close()
+
+	options, err := v1beta1.NewDevicePluginClient(conn).GetDevicePluginOptions(context.Background(), &v1beta1.Empty{})
+	if err != nil {
+		t.Fatalf("GetDevicePluginOptions failed: %v", err)
+	}
+	if !options.PreStartRequired {
+		t.Error("Expected the options served over the socket to reflect the plugin configuration")
+	}
+}
+
+// TestStartErrorTypes 测试启动失败时可以通过errors.Is区分注册失败、监听失败和设备初始化失败
+func TestStartErrorTypes(t *testing.T) {
+	// 没有kubelet时注册失败
+	plugin := NewPPUDevicePlugin("test.com/ppu", 1, t.TempDir())
+	err := plugin.Start()
+	if !errors.Is(err, ErrRegistration) || !errors.Is(err, ErrKubeletSocketNotFound) {
+		t.Errorf("Expected a registration failure, got %v", err)
+	}
+	if errors.Is(err, ErrSocketListen) {
+		t.Errorf("Registration failure must not be reported as a listen failure: %v", err)
+	}
+
+	// socket路径超过Unix socket长度上限时监听失败
+	plugin = NewPPUDevicePluginWithOptions("test.com/ppu", 1, t.TempDir(), Options{
+		SocketName: strings.Repeat("s", 120) + ".sock",
+	})
+	err = plugin.Start()
+	if !errors.Is(err, ErrSocketListen) {
+		t.Errorf("Expected a listen failure, got %v", err)
+	}
+	if errors.Is(err, ErrRegistration) {
+		t.Errorf("Listen failure must not be reported as a registration failure: %v", err)
+	}
+
+	plugin = NewPPUDevicePlugin("test.com/ppu", -1, t.TempDir())
+	if err := plugin.Start(); !errors.Is(err, ErrDeviceInit) {
+		t.Errorf("Expected a device initialization failure, got %v", err)
+	}
+}
+
+// TestRegisterKubeletSocketMissing 测试kubelet socket不存在时返回明确的错误
+func TestRegisterKubeletSocketMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	plugin := NewPPUDevicePlugin("test.com/ppu", 1, tmpDir)
+
+	err := plugin.register(context.Background())
+	if !errors.Is(err, ErrKubeletSocketNotFound) {
+		t.Fatalf("Expected ErrKubeletSocketNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), filepath.Join(tmpDir, KubeletSocket)) {
+		t.Errorf("Expected error to mention the socket path, got %v", err)
+	}
+}
+
+// TestRegisterRetryCanceled 测试注册重试期间取消上下文时Start立即返回
+func TestRegisterRetryCanceled(t *testing.T) {
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 1, t.TempDir(), Options{
+		RegisterRetries: 5,
+		RegisterBackoff: time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := plugin.StartContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected Start to return promptly after cancellation, took %s", elapsed)
+	}
+}
+
+// TestReregisterOnKubeletRestart 测试kubelet.sock重建后自动重新注册且去抖
+func TestReregisterOnKubeletRestart(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 1, tmpDir)
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop()
+
+	// 模拟kubelet重启：删除所有socket后快速重建两次
+	kubelet.Stop()
+	os.Remove(filepath.Join(tmpDir, KubeletSocket))
+	os.Remove(plugin.socket)
+	listener, err := net.Listen("unix", filepath.Join(tmpDir, KubeletSocket))
+	if err != nil {
+		t.Fatalf("Failed to recreate kubelet socket: %v", err)
+	}
+	listener.Close()
+	fake := startFakeKubelet(t, tmpDir)
+
+	select {
+	case request := <-fake.Requests():
+		if request.ResourceName != "test.com/ppu" {
+			t.Errorf("Expected resource test.com/ppu, got %s", request.ResourceName)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Plugin did not re-register after kubelet restart")
+	}
+
+	select {
+	case <-fake.Requests():
+		t.Error("Expected duplicate create events to be debounced into one registration")
+	case <-time.After(2 * kubeletSocketDebounce):
+	}
+
+	if _, err := os.Stat(plugin.socket); err != nil {
+		t.Errorf("Expected plugin socket to be recreated: %v", err)
+	}
+}
+
+// TestRecreatePluginSocket 测试插件socket被删除后自动重建、重新注册并保持可连接
+func TestRecreatePluginSocket(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, tmpDir)
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop()
+	<-kubelet.Requests()
+
+	if err := os.Remove(plugin.socket); err != nil {
+		t.Fatalf("Failed to remove plugin socket: %v", err)
+	}
+
+	select {
+	case request := <-kubelet.Requests():
+		if expected := SocketNameForResource("test.com/ppu"); request.Endpoint != expected {
+			t.Errorf("Expected endpoint %s, got %s", expected, request.Endpoint)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Plugin did not re-register after its socket was removed")
+	}
+
+	if _, err := os.Stat(plugin.socket); err != nil {
+		t.Fatalf("Expected plugin socket to be recreated: %v", err)
+	}
+	conn, err := plugin.dial(context.Background(), plugin.socket, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to recreated socket: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := v1beta1.NewDevicePluginClient(conn).GetDevicePluginOptions(context.Background(), &v1beta1.Empty{}); err != nil {
+		t.Errorf("GetDevicePluginOptions over recreated socket failed: %v", err)
+	}
+}
+
+// TestInitDevicesNUMATopology 测试设备按轮询方式分布到NUMA节点
+func TestInitDevicesNUMATopology(t *testing.T) {
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 4, t.TempDir(), Options{NUMANodes: 2})
+	if err := plugin.initDevices(); err != nil {
+		t.Fatalf("initDevices failed: %v", err)
+	}
+
+	expected := map[string]int64{"ppu-0": 0, "ppu-1": 1, "ppu-2": 0, "ppu-3": 1}
+	for deviceID, node := range expected {
+		topology := plugin.devices[deviceID].Topology
+		if topology == nil || len(topology.Nodes) != 1 {
+			t.Fatalf("Expected %s to report exactly one NUMA node, got %v", deviceID, topology)
+		}
+		if topology.Nodes[0].ID != node {
+			t.Errorf("Expected %s on NUMA node %d, got %d", deviceID, node, topology.Nodes[0].ID)
+		}
+	}
+}
+
+// TestInitDevicesOverrides 测试按设备覆盖初始健康状态和NUMA节点
+func TestInitDevicesOverrides(t *testing.T) {
+	node := int64(3)
+	plugin := newTestPlugin(t, 2, Options{
+		NUMANodes: 2,
+		DeviceOverrides: map[string]DeviceOverride{
+			"ppu-0": {Health: v1beta1.Unhealthy},
+			"ppu-1": {NUMANode: &node},
+		},
+	})
+
+	if health := plugin.devices["ppu-0"].Health; health != v1beta1.Unhealthy {
+		t.Errorf("Expected ppu-0 to be Unhealthy, got %s", health)
+	}
+	if topology := plugin.devices["ppu-0"].Topology; topology == nil || topology.Nodes[0].ID != 0 {
+		t.Errorf("Expected ppu-0 to keep the round-robin NUMA node 0, got %v", topology)
+	}
+	if topology := plugin.devices["ppu-1"].Topology; topology == nil || topology.Nodes[0].ID != 3 {
+		t.Errorf("Expected ppu-1 on NUMA node 3, got %v", topology)
+	}
+}
+
+// allocateInBackground 通过gRPC客户端在后台发起一次分配请求
+func allocateInBackground(t *testing.T, plugin *PPUDevicePlugin, deviceID string) <-chan error {
+	t.Helper()
+
+	conn, err := plugin.dial(context.Background(), plugin.socket, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to plugin: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := v1beta1.NewDevicePluginClient(conn).Allocate(context.Background(), &v1beta1.AllocateRequest{
+			ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{deviceID}}},
+		})
+		result <- err
+	}()
+	return result
+}
+
+// TestPreStartRequired 测试启用PreStartRequired后返回的插件选项与PreStart对设备ID的校验
+func TestPreStartRequired(t *testing.T) {
+	tests := []struct {
+		name             string
+		preStartRequired bool
+		deviceIDs        []string
+		wantCode         codes.Code
+	}{
+		{name: "disabled ignores unknown devices", deviceIDs: []string{"ppu-9"}, wantCode: codes.OK},
+		{name: "enabled accepts known devices", preStartRequired: true, deviceIDs: []string{"ppu-0", "ppu-1"}, wantCode: codes.OK},
+		{name: "enabled rejects unknown devices", preStartRequired: true, deviceIDs: []string{"ppu-0", "ppu-9"}, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, 2, Options{PreStartRequired: tt.preStartRequired})
+
+			options, err := plugin.GetDevicePluginOptions(context.Background(), &v1beta1.Empty{})
+			if err != nil {
+				t.Fatalf("GetDevicePluginOptions failed: %v", err)
+			}
+			if options.PreStartRequired != tt.preStartRequired {
+				t.Errorf("Expected PreStartRequired %v, got %v", tt.preStartRequired, options.PreStartRequired)
+			}
+
+			request := &v1beta1.PreStartContainerRequest{DevicesIDs: tt.deviceIDs}
+			_, err = plugin.PreStartContainer(context.Background(), request)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s (%v)", tt.wantCode, code, err)
+			}
+		})
+	}
+}
+
+// TestPreferredAllocationOption 测试首选分配的声明与禁用后GetPreferredAllocation返回Unimplemented
+func TestPreferredAllocationOption(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disabled=%v", disabled), func(t *testing.T) {
+			plugin := newTestPlugin(t, 2, Options{DisablePreferredAllocation: disabled})
+
+			options, err := plugin.GetDevicePluginOptions(context.Background(), &v1beta1.Empty{})
+			if err != nil {
+				t.Fatalf("GetDevicePluginOptions failed: %v", err)
+			}
+			if options.GetPreferredAllocationAvailable == disabled {
+				t.Errorf("Expected GetPreferredAllocationAvailable %v, got %v", !disabled, options.GetPreferredAllocationAvailable)
+			}
+
+			_, err = plugin.GetPreferredAllocation(context.Background(), &v1beta1.PreferredAllocationRequest{
+				ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{
+					{AvailableDeviceIDs: []string{"ppu-0", "ppu-1"}, AllocationSize: 1},
+				},
+			})
+			wantCode := codes.OK
+			if disabled {
+				wantCode = codes.Unimplemented
+			}
+			if code := status.Code(err); code != wantCode {
+				t.Errorf("Expected code %s, got %s (%v)", wantCode, code, err)
+			}
+		})
+	}
+}
+
+// TestStopDrainsInFlightAllocate 测试停止插件时等待进行中的Allocate完成
+func TestStopDrainsInFlightAllocate(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{AllocateLatency: 200 * time.Millisecond})
+	if err := plugin.serve(); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+
+	result := allocateInBackground(t, plugin, "ppu-0")
+	time.Sleep(50 * time.Millisecond)
+	plugin.Stop()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected in-flight Allocate to complete, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Allocate did not return")
+	}
+}
+
+// TestStopForcesAfterTimeout 测试优雅停止超时后强制停止
+func TestStopForcesAfterTimeout(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{
+		AllocateLatency: 5 * time.Second,
+		ShutdownTimeout: 50 * time.Millisecond,
+	})
+	if err := plugin.serve(); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+
+	result := allocateInBackground(t, plugin, "ppu-0")
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	plugin.Stop()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Stop to force shutdown after the timeout, took %s", elapsed)
+	}
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("Expected the aborted Allocate to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Allocate did not return")
+	}
+}
+
+// TestGRPCHealthService 测试gRPC健康检查服务在运行时为SERVING，停止后为NOT_SERVING
+func TestGRPCHealthService(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+	if err := plugin.serve(); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+	defer plugin.stopServer()
+
+	conn, err := plugin.dial(context.Background(), plugin.socket, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to plugin: %v", err)
+	}
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health Check failed: %v", err)
+	}
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %s", response.Status)
+	}
+
+	close(plugin.stop)
+	deadline := time.Now().Add(time.Second)
+	for {
+		response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		if err == nil && response.Status == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected NOT_SERVING after stop, got %v, %v", response, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStartSocketPathNotWritable 测试socket目录不可写时启动立即返回清晰的错误
+func TestStartSocketPathNotWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+
+	dir := filepath.Join(t.TempDir(), "readonly")
+	if err := os.Mkdir(dir, 0555); err != nil {
+		t.Fatalf("Failed to create read-only directory: %v", err)
+	}
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, dir)
+	err := plugin.Start()
+	if err == nil {
+		plugin.Stop()
+		t.Fatal("Expected Start to fail on a read-only socket path")
+	}
+	if expected := fmt.Sprintf("socket path %s not writable: permission denied", dir); err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err)
+	}
+}
+
+// TestStartSocketPathNotDirectory 测试socket路径无法创建时启动立即返回清晰的错误
+func TestStartSocketPathNotDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	dir := filepath.Join(file, "device-plugins")
+
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, dir)
+	err := plugin.Start()
+	if err == nil {
+		plugin.Stop()
+		t.Fatal("Expected Start to fail when the socket path cannot be created")
+	}
+	if expected := fmt.Sprintf("socket path %s not writable: not a directory", dir); err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err)
+	}
+	if len(plugin.devices) != 0 {
+		t.Error("Expected the preflight check to fail before devices are initialized")
+	}
+}
+
+// TestRegisterRetriesTransientFailures 测试注册失败后按退避重试直到成功
+func TestRegisterRetriesTransientFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+	kubelet.FailNext(2)
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{
+		RegisterRetries: 5,
+		RegisterBackoff: 10 * time.Millisecond,
+	})
+
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	start := time.Now()
+	if err := plugin.register(context.Background()); err != nil {
+		t.Fatalf("Expected registration to succeed after retries, got %v", err)
+	}
+	// 两次退避：10ms + 20ms
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected exponential backoff between attempts, took only %s", elapsed)
+	}
+
+	if attempts := kubelet.Attempts(); attempts != 3 {
+		t.Errorf("Expected 3 registration attempts, got %d", attempts)
+	}
+
+	warnings := 0
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == log.WarnLevel && strings.Contains(entry.Message, "Registration attempt") {
+			warnings++
+		}
+	}
+	if warnings != 2 {
+		t.Errorf("Expected 2 warnings for failed attempts, got %d", warnings)
+	}
+}
+
+// TestRegisterRetriesExhausted 测试重试次数用尽后返回最后一次的错误
+func TestRegisterRetriesExhausted(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubelet := startFakeKubelet(t, tmpDir)
+	kubelet.FailNext(10)
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 2, tmpDir, Options{
+		RegisterRetries: 2,
+		RegisterBackoff: time.Millisecond,
+	})
+
+	if err := plugin.register(context.Background()); err == nil {
+		t.Fatal("Expected registration to fail after exhausting retries")
+	}
+
+	if attempts := kubelet.Attempts(); attempts != 3 {
+		t.Errorf("Expected 1 attempt plus 2 retries, got %d", attempts)
+	}
+}
@@ -0,0 +1,147 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// podResourcesServer 以kubelet PodResources API兼容的形式上报设备分配情况
+// 分配对象"allocation-N/container-i"映射为Pod "allocation-N"中的容器"container-i"
+type podResourcesServer struct {
+	podresourcesv1.UnimplementedPodResourcesListerServer
+	plugin *PPUDevicePlugin
+}
+
+// List 返回所有分配对象及其持有的设备
+func (s *podResourcesServer) List(ctx context.Context, request *podresourcesv1.ListPodResourcesRequest) (*podresourcesv1.ListPodResourcesResponse, error) {
+	return &podresourcesv1.ListPodResourcesResponse{
+		PodResources: s.plugin.podResources(),
+	}, nil
+}
+
+// Get 返回指定分配对象持有的设备
+func (s *podResourcesServer) Get(ctx context.Context, request *podresourcesv1.GetPodResourcesRequest) (*podresourcesv1.GetPodResourcesResponse, error) {
+	for _, pod := range s.plugin.podResources() {
+		if pod.Name == request.PodName && pod.Namespace == request.PodNamespace {
+			return &podresourcesv1.GetPodResourcesResponse{PodResources: pod}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "pod %s/%s not found", request.PodNamespace, request.PodName)
+}
+
+// GetAllocatableResources 返回插件管理的全部设备
+func (s *podResourcesServer) GetAllocatableResources(ctx context.Context, request *podresourcesv1.AllocatableResourcesRequest) (*podresourcesv1.AllocatableResourcesResponse, error) {
+	devices := s.plugin.deviceList()
+	deviceIDs := make([]string, 0, len(devices))
+	for _, device := range devices {
+		deviceIDs = append(deviceIDs, device.ID)
+	}
+
+	return &podresourcesv1.AllocatableResourcesResponse{
+		Devices: []*podresourcesv1.ContainerDevices{{
+			ResourceName: s.plugin.resourceName,
+			DeviceIds:    deviceIDs,
+		}},
+	}, nil
+}
+
+// podResources 将分配记录按分配对象聚合为PodResources
+func (p *PPUDevicePlugin) podResources() []*podresourcesv1.PodResources {
+	p.mu.RLock()
+	containers := make(map[string][]string)
+	for deviceID, owner := range p.allocated {
+		containers[owner] = append(containers[owner], deviceID)
+	}
+	p.mu.RUnlock()
+
+	owners := make([]string, 0, len(containers))
+	for owner := range containers {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool { return deviceIDLess(owners[i], owners[j]) })
+
+	pods := []*podresourcesv1.PodResources{}
+	index := make(map[string]*podresourcesv1.PodResources)
+	for _, owner := range owners {
+		podName, containerName := owner, ""
+		if i := strings.Index(owner, "/"); i >= 0 {
+			podName, containerName = owner[:i], owner[i+1:]
+		}
+
+		pod, ok := index[podName]
+		if !ok {
+			pod = &podresourcesv1.PodResources{Name: podName}
+			index[podName] = pod
+			pods = append(pods, pod)
+		}
+
+		deviceIDs := containers[owner]
+		sort.Slice(deviceIDs, func(i, j int) bool { return deviceIDLess(deviceIDs[i], deviceIDs[j]) })
+		pod.Containers = append(pod.Containers, &podresourcesv1.ContainerResources{
+			Name: containerName,
+			Devices: []*podresourcesv1.ContainerDevices{{
+				ResourceName: p.resourceName,
+				DeviceIds:    deviceIDs,
+			}},
+		})
+	}
+	return pods
+}
+
+// startPodResourcesServer 在独立socket上启动PodResources兼容服务
+func (p *PPUDevicePlugin) startPodResourcesServer() error {
+	socket := p.opts.PodResourcesSocket
+	if socket == "" {
+		p.log.Debug("PodResources server disabled")
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+		return fmt.Errorf("failed to create pod resources socket directory: %v", err)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove existing pod resources socket: %v", err)
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on pod resources socket %s: %v", socket, err)
+	}
+
+	server := grpc.NewServer()
+	podresourcesv1.RegisterPodResourcesListerServer(server, &podResourcesServer{plugin: p})
+	p.podResourcesServer = server
+
+	go func() {
+		p.log.Infof("PodResources server listening on socket: %s", socket)
+		if err := server.Serve(listener); err != nil {
+			p.log.Errorf("PodResources server failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// stopPodResourcesServer 停止PodResources兼容服务并清理socket文件
+func (p *PPUDevicePlugin) stopPodResourcesServer() {
+	if p.podResourcesServer == nil {
+		return
+	}
+
+	p.podResourcesServer.Stop()
+	p.podResourcesServer = nil
+
+	if err := os.Remove(p.opts.PodResourcesSocket); err != nil && !os.IsNotExist(err) {
+		p.log.Warnf("Failed to remove pod resources socket file: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package deviceplugin
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// TestPodResourcesList 测试通过PodResources兼容服务查询分配结果
+func TestPodResourcesList(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "pod-resources.sock")
+	plugin := newTestPlugin(t, 4, Options{PodResourcesSocket: socket})
+	if err := plugin.startPodResourcesServer(); err != nil {
+		t.Fatalf("startPodResourcesServer failed: %v", err)
+	}
+	defer plugin.stopPodResourcesServer()
+
+	allocate(t, plugin, "ppu-2", "ppu-0")
+
+	conn, err := plugin.dial(context.Background(), socket, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to pod resources server: %v", err)
+	}
+	defer conn.Close()
+
+	client := podresourcesv1.NewPodResourcesListerClient(conn)
+	response, err := client.List(context.Background(), &podresourcesv1.ListPodResourcesRequest{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(response.PodResources) != 1 {
+		t.Fatalf("Expected 1 pod, got %d", len(response.PodResources))
+	}
+	pod := response.PodResources[0]
+	if pod.Name != "allocation-1" || len(pod.Containers) != 1 {
+		t.Fatalf("Unexpected pod resources: %v", pod)
+	}
+	container := pod.Containers[0]
+	if container.Name != "container-0" || len(container.Devices) != 1 {
+		t.Fatalf("Unexpected container resources: %v", container)
+	}
+	if container.Devices[0].ResourceName != "test.com/ppu" {
+		t.Errorf("Expected resource name test.com/ppu, got %s", container.Devices[0].ResourceName)
+	}
+	if expected := []string{"ppu-0", "ppu-2"}; !reflect.DeepEqual(container.Devices[0].DeviceIds, expected) {
+		t.Errorf("Expected devices %v, got %v", expected, container.Devices[0].DeviceIds)
+	}
+}
@@ -0,0 +1,62 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// pprofHandler 返回性能分析HTTP服务的路由，仅包含net/http/pprof的处理器
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// startPprofServer 在Options.PprofAddr上启动性能分析HTTP服务，未配置地址时不启动
+func (p *PPUDevicePlugin) startPprofServer() error {
+	if p.opts.PprofAddr == "" {
+		p.log.Debug("Pprof server disabled")
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", p.opts.PprofAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on pprof address %s: %v", p.opts.PprofAddr, err)
+	}
+
+	p.pprofServer = &http.Server{
+		Handler:           pprofHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		p.log.Warnf("Pprof server listening on %s; do not expose it outside trusted networks", listener.Addr())
+		if err := p.pprofServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			p.log.Errorf("Pprof server failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// stopPprofServer 关闭性能分析HTTP服务
+func (p *PPUDevicePlugin) stopPprofServer() {
+	if p.pprofServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.pprofServer.Shutdown(ctx); err != nil {
+		p.log.Warnf("Failed to shut down pprof server: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestPprofServer 测试性能分析服务默认关闭，启用后/debug/pprof/返回200
+func TestPprofServer(t *testing.T) {
+	disabled := newTestPlugin(t, 1, Options{})
+	if err := disabled.startPprofServer(); err != nil {
+		t.Fatalf("startPprofServer failed: %v", err)
+	}
+	if disabled.pprofServer != nil {
+		t.Error("Expected pprof server to be disabled by default")
+	}
+
+	plugin := newTestPlugin(t, 1, Options{PprofAddr: "127.0.0.1:0"})
+	if err := plugin.startPprofServer(); err != nil {
+		t.Fatalf("startPprofServer failed: %v", err)
+	}
+	if plugin.pprofServer == nil {
+		t.Fatal("Expected pprof server to be started when PprofAddr is set")
+	}
+	defer plugin.stopPprofServer()
+
+	server := httptest.NewServer(plugin.pprofServer.Handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("GET /debug/pprof/ failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected /debug/pprof/ to return 200, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,43 @@
+package deviceplugin
+
+import (
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// allocatableLocked 判断Allocate是否会授予该设备，调用方需持有p.mu
+func (p *PPUDevicePlugin) allocatableLocked(deviceID string) (bool, string) {
+	device, exists := p.devices[deviceID]
+	if !exists {
+		return false, "unknown device"
+	}
+	if p.reserved[deviceID] {
+		return false, "device is reserved"
+	}
+	if device.Health != v1beta1.Healthy {
+		return false, "device is " + device.Health
+	}
+	if owner, taken := p.allocated[deviceID]; taken {
+		return false, "already allocated to " + owner
+	}
+	return true, ""
+}
+
+// allocatableDevices 从候选设备中过滤掉Allocate会拒绝的设备，claimed为同一请求中已分给其他容器的设备
+func (p *PPUDevicePlugin) allocatableDevices(available []string, claimed map[string]bool) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	allocatable := make([]string, 0, len(available))
+	for _, deviceID := range available {
+		if claimed[deviceID] {
+			p.log.Debugf("Excluding device %s from preferred allocation: claimed by another container", deviceID)
+			continue
+		}
+		if ok, reason := p.allocatableLocked(deviceID); !ok {
+			p.log.Debugf("Excluding device %s from preferred allocation: %s", deviceID, reason)
+			continue
+		}
+		allocatable = append(allocatable, deviceID)
+	}
+	return allocatable
+}
@@ -0,0 +1,49 @@
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestPreferredAllocationExcludesUnallocatable 测试首选分配排除已分配和不健康的设备
+func TestPreferredAllocationExcludesUnallocatable(t *testing.T) {
+	plugin := newTestPlugin(t, 6, Options{})
+	allocate(t, plugin, "ppu-0")
+	plugin.setDeviceHealth("ppu-1", v1beta1.Unhealthy)
+
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5"}
+	selected := preferredAllocation(t, plugin, available, nil, 2)
+	if expected := []string{"ppu-2", "ppu-3"}; !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected %v, got %v", expected, selected)
+	}
+
+	// 首选结果应能被Allocate完整授予
+	response := allocate(t, plugin, selected...)
+	if got := response.ContainerResponses[0].Envs[DefaultEnvDevicesKey]; got != "ppu-2,ppu-3" {
+		t.Errorf("Expected Allocate to grant the preferred devices, got %s", got)
+	}
+}
+
+// TestPreferredAllocationAcrossContainers 测试同一请求中的多个容器不会得到相同的设备
+func TestPreferredAllocationAcrossContainers(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}
+
+	response, err := plugin.GetPreferredAllocation(context.Background(), &v1beta1.PreferredAllocationRequest{
+		ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{
+			{AvailableDeviceIDs: available, AllocationSize: 2},
+			{AvailableDeviceIDs: available, AllocationSize: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GetPreferredAllocation failed: %v", err)
+	}
+
+	first, second := response.ContainerResponses[0].DeviceIDs, response.ContainerResponses[1].DeviceIDs
+	if !reflect.DeepEqual(first, []string{"ppu-0", "ppu-1"}) || !reflect.DeepEqual(second, []string{"ppu-2", "ppu-3"}) {
+		t.Errorf("Expected disjoint selections, got %v and %v", first, second)
+	}
+}
@@ -0,0 +1,151 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// AllocationStrategy 可插拔的首选分配策略。available为按设备ID自然顺序排序的候选设备，
+// devices为候选设备和必须包含设备的副本（含健康状态和拓扑信息），返回的结果应以mustInclude开头且不超过size个设备
+type AllocationStrategy interface {
+	Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string
+}
+
+// pluginStrategy 依赖插件状态（温度、互联组、供电域配置）的内置策略，selectPreferred优先调用selectFor
+type pluginStrategy interface {
+	selectFor(p *PPUDevicePlugin, available, mustInclude []string, size int) []string
+}
+
+// PackedStrategy 默认分配策略：先包含必须包含的设备，再按设备序号从小到大补足
+type PackedStrategy struct{}
+
+// Select 实现AllocationStrategy
+func (PackedStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	return selectPacked(available, mustInclude, size)
+}
+
+// selectFor 配置了供电域策略时按供电域选择设备
+func (s PackedStrategy) selectFor(p *PPUDevicePlugin, available, mustInclude []string, size int) []string {
+	if p.opts.PowerStrategy != "" {
+		return selectByPowerDomain(available, mustInclude, size, p.opts.PowerDomains, p.opts.PowerStrategy)
+	}
+	return s.Select(available, mustInclude, size, nil)
+}
+
+// spreadStrategy 将设备轮流分散到各NUMA节点
+type spreadStrategy struct{}
+
+// Select 实现AllocationStrategy
+func (spreadStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	return selectSpread(available, mustInclude, size, topologyNodeSets(devices))
+}
+
+// numaPackedStrategy 尽量将设备集中在同一NUMA节点
+type numaPackedStrategy struct{}
+
+// Select 实现AllocationStrategy
+func (numaPackedStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	return selectNUMAPacked(available, mustInclude, size, topologyNodeSets(devices))
+}
+
+// interconnectStrategy 尽量将设备集中在同一互联组内，互联组与NUMA节点的选择规则相同
+type interconnectStrategy struct{}
+
+// Select 实现AllocationStrategy，缺少互联组配置时每个设备视为独立的组
+func (interconnectStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	return selectNUMAPacked(available, mustInclude, size, singleNodeSets(groupIndexes(append(append([]string{}, available...), mustInclude...), nil, 0)))
+}
+
+// selectFor 按插件配置的互联组选择设备
+func (interconnectStrategy) selectFor(p *PPUDevicePlugin, available, mustInclude []string, size int) []string {
+	return selectNUMAPacked(available, mustInclude, size, singleNodeSets(p.interconnectGroups(append(append([]string{}, available...), mustInclude...))))
+}
+
+// temperatureAwareStrategy 优先选择温度最低的设备
+type temperatureAwareStrategy struct{}
+
+// Select 实现AllocationStrategy，缺少温度信息时按设备序号选择
+func (temperatureAwareStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	return selectPacked(available, mustInclude, size)
+}
+
+// selectFor 按插件记录的设备温度从低到高选择设备
+func (temperatureAwareStrategy) selectFor(p *PPUDevicePlugin, available, mustInclude []string, size int) []string {
+	return selectPacked(p.sortByTemperature(available), mustInclude, size)
+}
+
+var (
+	// strategiesMu保护strategies
+	strategiesMu sync.RWMutex
+	// strategies 已注册的分配策略，包括全部内置策略和通过RegisterStrategy注册的策略
+	strategies = map[string]AllocationStrategy{
+		AllocationStrategyPacked:           PackedStrategy{},
+		AllocationStrategySpread:           spreadStrategy{},
+		AllocationStrategyNUMAPacked:       numaPackedStrategy{},
+		AllocationStrategyInterconnect:     interconnectStrategy{},
+		AllocationStrategyTemperatureAware: temperatureAwareStrategy{},
+	}
+)
+
+// RegisterStrategy 注册自定义分配策略，之后可通过Options.AllocationStrategy（--allocation-strategy）按名称选用。
+// 使用内置策略的名称时替换该内置策略；名称为空、策略为nil或自定义名称已被注册时panic
+func RegisterStrategy(name string, s AllocationStrategy) {
+	if name == "" || s == nil {
+		panic("deviceplugin: RegisterStrategy requires a name and a strategy")
+	}
+
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	if _, exists := strategies[name]; exists && !contains(AllocationStrategies, name) {
+		panic(fmt.Sprintf("deviceplugin: allocation strategy %q already registered", name))
+	}
+	strategies[name] = s
+}
+
+// RegisteredStrategies 返回注册表中的策略名称，内置策略按AllocationStrategies的顺序在前，自定义策略按名称排序
+func RegisteredStrategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	names := []string{}
+	for _, name := range AllocationStrategies {
+		if _, exists := strategies[name]; exists {
+			names = append(names, name)
+		}
+	}
+	custom := []string{}
+	for name := range strategies {
+		if !contains(AllocationStrategies, name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// lookupStrategy 按名称查找已注册的分配策略
+func lookupStrategy(name string) (AllocationStrategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	s, exists := strategies[name]
+	return s, exists
+}
+
+// strategyDevices 返回供自定义策略使用的设备副本
+func (p *PPUDevicePlugin) strategyDevices(deviceIDs []string) map[string]*v1beta1.Device {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	devices := make(map[string]*v1beta1.Device, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		if device, exists := p.devices[deviceID]; exists {
+			devices[deviceID] = copyDevice(device)
+		}
+	}
+	return devices
+}
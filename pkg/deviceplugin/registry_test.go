@@ -0,0 +1,80 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// reverseStrategy 测试用策略：从序号最大的健康设备开始选择
+type reverseStrategy struct {
+	seen map[string]*v1beta1.Device
+}
+
+func (s *reverseStrategy) Select(available, mustInclude []string, size int, devices map[string]*v1beta1.Device) []string {
+	s.seen = devices
+	selected := append([]string{}, mustInclude...)
+	for i := len(available) - 1; i >= 0 && len(selected) < size; i-- {
+		if devices[available[i]].Health == v1beta1.Healthy && !contains(selected, available[i]) {
+			selected = append(selected, available[i])
+		}
+	}
+	return selected
+}
+
+// TestRegisterStrategy 测试注册的自定义策略按名称被GetPreferredAllocation使用
+func TestRegisterStrategy(t *testing.T) {
+	strategy := &reverseStrategy{}
+	RegisterStrategy("test-reverse", strategy)
+	defer func() {
+		strategiesMu.Lock()
+		delete(strategies, "test-reverse")
+		strategiesMu.Unlock()
+	}()
+
+	if names := RegisteredStrategies(); !reflect.DeepEqual(names, append(append([]string{}, AllocationStrategies...), "test-reverse")) {
+		t.Errorf("Expected built-in strategies followed by test-reverse, got %v", names)
+	}
+
+	plugin := newTestPlugin(t, 4, Options{AllocationStrategy: "test-reverse"})
+	selected := preferredAllocation(t, plugin, []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}, []string{"ppu-1"}, 3)
+	if expected := []string{"ppu-1", "ppu-3", "ppu-2"}; !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected custom strategy selection %v, got %v", expected, selected)
+	}
+	if len(strategy.seen) != 4 {
+		t.Errorf("Expected the strategy to receive 4 devices, got %d", len(strategy.seen))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a custom strategy name twice to panic")
+		}
+	}()
+	RegisterStrategy("test-reverse", strategy)
+}
+
+// TestRegisterStrategyOverridesBuiltin 测试使用内置策略名称注册时替换对应的内置策略
+func TestRegisterStrategyOverridesBuiltin(t *testing.T) {
+	lookup := func() AllocationStrategy {
+		s, _ := lookupStrategy(AllocationStrategySpread)
+		return s
+	}
+	builtin := lookup()
+	defer func() {
+		strategiesMu.Lock()
+		strategies[AllocationStrategySpread] = builtin
+		strategiesMu.Unlock()
+	}()
+
+	RegisterStrategy(AllocationStrategySpread, &reverseStrategy{})
+	if names := RegisteredStrategies(); !reflect.DeepEqual(names, AllocationStrategies) {
+		t.Errorf("Expected overriding a built-in to keep the strategy list unchanged, got %v", names)
+	}
+
+	plugin := newTestPlugin(t, 4, Options{NUMANodes: 2, AllocationStrategy: AllocationStrategySpread})
+	selected := preferredAllocation(t, plugin, []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}, nil, 2)
+	if expected := []string{"ppu-3", "ppu-2"}; !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected the overriding strategy selection %v, got %v", expected, selected)
+	}
+}
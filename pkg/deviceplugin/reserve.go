@@ -0,0 +1,99 @@
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ReserveDevices 将设备移出可分配范围（如维护窗口），设备不再上报给kubelet且分配会被拒绝，但不会标记为不健康
+func (p *PPUDevicePlugin) ReserveDevices(ids []string) error {
+	if err := p.setReserved(ids, true); err != nil {
+		return err
+	}
+	p.log.Infof("Reserved devices %v", ids)
+	return nil
+}
+
+// UnreserveDevices 将预留的设备放回可分配范围
+func (p *PPUDevicePlugin) UnreserveDevices(ids []string) error {
+	if err := p.setReserved(ids, false); err != nil {
+		return err
+	}
+	p.log.Infof("Unreserved devices %v", ids)
+	return nil
+}
+
+// ReservedDevices 返回按设备ID排序的预留设备
+func (p *PPUDevicePlugin) ReservedDevices() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ids := make([]string, 0, len(p.reserved))
+	for deviceID := range p.reserved {
+		ids = append(ids, deviceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+	return ids
+}
+
+// setReserved 更新设备的预留状态，存在未知设备时不做任何修改
+func (p *PPUDevicePlugin) setReserved(ids []string, reserved bool) error {
+	p.mu.Lock()
+	for _, deviceID := range ids {
+		if _, exists := p.devices[deviceID]; !exists {
+			p.mu.Unlock()
+			return fmt.Errorf("unknown device %s", deviceID)
+		}
+	}
+	for _, deviceID := range ids {
+		if reserved {
+			p.reserved[deviceID] = true
+		} else {
+			delete(p.reserved, deviceID)
+		}
+	}
+	if reserved {
+		p.invalidateAllocationCacheLocked(ids...)
+	}
+	p.mu.Unlock()
+
+	p.notifyListAndWatch()
+	return nil
+}
+
+// notifyListAndWatch 通知ListAndWatch重新上报设备列表，多次通知会被合并
+func (p *PPUDevicePlugin) notifyListAndWatch() {
+	select {
+	case p.listChanged <- struct{}{}:
+	default:
+	}
+}
+
+// handleReserve 处理POST /reserve和POST /unreserve，请求体为设备ID的JSON数组
+func (p *PPUDevicePlugin) handleReserve(reserve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var ids []string
+		if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		update := p.UnreserveDevices
+		if reserve {
+			update = p.ReserveDevices
+		}
+		if err := update(ids); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writeJSON(w, p.log, http.StatusOK, map[string][]string{"reserved": p.ReservedDevices()})
+	}
+}
@@ -0,0 +1,108 @@
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// advertisedIDs 返回响应中上报的设备ID
+func advertisedIDs(response *v1beta1.ListAndWatchResponse) []string {
+	ids := make([]string, 0, len(response.Devices))
+	for _, device := range response.Devices {
+		ids = append(ids, device.ID)
+	}
+	return ids
+}
+
+// TestReserveDevicesListAndWatch 测试预留的设备从上报列表中消失，取消预留后重新出现
+func TestReserveDevicesListAndWatch(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{})
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	if err := plugin.ReserveDevices([]string{"ppu-1"}); err != nil {
+		t.Fatalf("ReserveDevices failed: %v", err)
+	}
+	if ids := advertisedIDs(stream.next(t)); !reflect.DeepEqual(ids, []string{"ppu-0", "ppu-2"}) {
+		t.Errorf("Expected reserved ppu-1 to be hidden, got %v", ids)
+	}
+
+	if err := plugin.UnreserveDevices([]string{"ppu-1"}); err != nil {
+		t.Fatalf("UnreserveDevices failed: %v", err)
+	}
+	if ids := advertisedIDs(stream.next(t)); !reflect.DeepEqual(ids, []string{"ppu-0", "ppu-1", "ppu-2"}) {
+		t.Errorf("Expected ppu-1 to be advertised again, got %v", ids)
+	}
+}
+
+// TestAllocateReservedDevice 测试分配预留设备被拒绝，且设备仍为健康状态
+func TestAllocateReservedDevice(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{})
+	if err := plugin.ReserveDevices([]string{"ppu-0"}); err != nil {
+		t.Fatalf("ReserveDevices failed: %v", err)
+	}
+
+	_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a reserved device, got %v", err)
+	}
+	if health, _ := plugin.deviceHealth("ppu-0"); health != v1beta1.Healthy {
+		t.Errorf("Expected reserved device to stay Healthy, got %s", health)
+	}
+
+	if err := plugin.ReserveDevices([]string{"ppu-1", "ppu-9"}); err == nil {
+		t.Error("Expected an error when reserving an unknown device")
+	}
+	if reserved := plugin.ReservedDevices(); !reflect.DeepEqual(reserved, []string{"ppu-0"}) {
+		t.Errorf("Expected a failed reservation to change nothing, got %v", reserved)
+	}
+}
+
+// TestReserveEndpoint 测试通过管理接口预留和取消预留设备
+func TestReserveEndpoint(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{EnableAdminAPI: true})
+	handler := plugin.adminHandler()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post("/reserve", `["ppu-0", "ppu-2"]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result map[string][]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !reflect.DeepEqual(result["reserved"], []string{"ppu-0", "ppu-2"}) {
+		t.Errorf("Expected reserved [ppu-0 ppu-2], got %v", result["reserved"])
+	}
+
+	if rec := post("/unreserve", `["ppu-0"]`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if reserved := plugin.ReservedDevices(); !reflect.DeepEqual(reserved, []string{"ppu-2"}) {
+		t.Errorf("Expected reserved [ppu-2], got %v", reserved)
+	}
+
+	if rec := post("/reserve", `{"bad"`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", rec.Code)
+	}
+	if rec := post("/reserve", `["ppu-9"]`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown device, got %d", rec.Code)
+	}
+}
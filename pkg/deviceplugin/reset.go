@@ -0,0 +1,55 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// Reset 将模拟设备恢复到全新状态：清空分配记录和预留，重新创建全部设备并标记为健康，然后通知ListAndWatch重新上报；
+// 配置的不健康、初始健康和损坏设备不会重新注入。设备在同一写锁内重建，并发的调用不会看到设备为空的中间状态，重置前开始的Allocate不会提交
+func (p *PPUDevicePlugin) Reset() {
+	p.mu.Lock()
+	released := len(p.allocated)
+	for deviceID := range p.devices {
+		p.metrics.deviceUtilization.DeleteLabelValues(deviceID)
+	}
+	p.devices = make(map[string]*v1beta1.Device)
+	p.allocated = make(map[string]string)
+	p.busyUntil = make(map[string]time.Time)
+	p.reserved = make(map[string]bool)
+	p.parents = make(map[string]string)
+	p.indexes = make(map[string]int)
+	p.allocationCache = make(map[string]*cachedAllocation)
+	p.utilization = make(map[string]float64)
+	p.temperature = make(map[string]float64)
+	p.attributes = make(map[string]DeviceAttributes)
+	p.stickyUnhealthy = make(map[string]bool)
+	p.permanentlyDead = make(map[string]bool)
+	for i := 0; i < p.deviceCount; i++ {
+		p.addDeviceLocked(i)
+	}
+	// 设备覆盖中的健康状态同样只在启动时生效，重置后所有设备均为健康
+	for _, device := range p.devices {
+		device.Health = v1beta1.Healthy
+	}
+	p.resetGeneration++
+	p.updateDeviceGaugesLocked()
+	p.mu.Unlock()
+
+	p.saveState()
+	p.notifyListAndWatch()
+	p.log.Infof("Device plugin reset: released %d allocations", released)
+}
+
+// handleReset 处理POST /reset，返回重置后的设备视图
+func (p *PPUDevicePlugin) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	p.Reset()
+	writeJSON(w, p.log, http.StatusOK, p.Snapshot())
+}
@@ -0,0 +1,127 @@
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestReset 测试POST /reset清空分配记录和预留，并将所有设备恢复为健康
+func TestReset(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{EnableAdminAPI: true})
+	stream := runListAndWatch(t, plugin)
+	stream.next(t)
+
+	allocate(t, plugin, "ppu-0")
+	plugin.setDeviceHealth("ppu-1", v1beta1.Unhealthy)
+	if err := plugin.ReserveDevices([]string{"ppu-2"}); err != nil {
+		t.Fatalf("ReserveDevices failed: %v", err)
+	}
+	stream.next(t)
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reset", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Invalid reset response: %v", err)
+	}
+	for _, device := range snapshot.Devices {
+		if device.Health != v1beta1.Healthy || device.Allocated || device.Reserved {
+			t.Errorf("Expected pristine device after reset, got %+v", device)
+		}
+	}
+
+	response := stream.next(t)
+	if len(response.Devices) != 3 {
+		t.Fatalf("Expected all 3 devices to be re-advertised, got %d", len(response.Devices))
+	}
+	for _, device := range response.Devices {
+		if device.Health != v1beta1.Healthy {
+			t.Errorf("Expected %s to be advertised Healthy after reset, got %s", device.ID, device.Health)
+		}
+	}
+
+	// 重置后之前分配的设备可以再次分配
+	allocate(t, plugin, "ppu-0")
+
+	rec = httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reset", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /reset to be rejected, got %d", rec.Code)
+	}
+}
+
+// TestResetClearsInjectedHealth 测试重置后配置注入的不健康和损坏设备同样恢复为健康且可以分配
+func TestResetClearsInjectedHealth(t *testing.T) {
+	plugin := newTestPlugin(t, 3, Options{
+		UnhealthyDevices: []string{"ppu-0"},
+		DeadDevices:      []string{"ppu-1"},
+	})
+	if health := plugin.Snapshot().Devices[0].Health; health != v1beta1.Unhealthy {
+		t.Fatalf("Expected ppu-0 to start Unhealthy, got %s", health)
+	}
+
+	plugin.Reset()
+
+	for _, device := range plugin.Snapshot().Devices {
+		if device.Health != v1beta1.Healthy {
+			t.Errorf("Expected %s to be Healthy after reset, got %s", device.ID, device.Health)
+		}
+	}
+	allocate(t, plugin, "ppu-0", "ppu-1")
+}
+
+// TestResetDuringAllocate 测试Allocate与Reset并发执行时不会看到空的设备列表，也不会返回未分配任何设备的成功响应
+func TestResetDuringAllocate(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+
+	done := make(chan struct{})
+	resetDone := make(chan struct{})
+	go func() {
+		defer close(resetDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			plugin.Reset()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		deviceID := fmt.Sprintf("ppu-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				response, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+					ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{deviceID}}},
+				})
+				if err == nil {
+					if got := response.ContainerResponses[0].Envs[DefaultEnvCountKey]; got != "1" {
+						t.Errorf("Expected successful Allocate of %s to return 1 device, got %s", deviceID, got)
+					}
+					plugin.releaseDevices([]string{deviceID})
+				}
+				if ids := deviceIDs(plugin); len(ids) != 4 {
+					t.Errorf("Expected 4 advertised devices during reset, got %v", ids)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	<-resetDone
+}
@@ -0,0 +1,67 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// dnsLabelRegexp DNS-1123标签，子域名的每一段须满足该格式
+	dnsLabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// resourceNameRegexp 扩展资源名称中"/"之后的部分
+	resourceNameRegexp = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+)
+
+// ValidateResourceName 校验资源名称符合Kubernetes扩展资源格式domain/name，
+// domain为DNS子域名且不属于kubernetes.io命名空间，name不超过63个字符
+func ValidateResourceName(resourceName string) error {
+	domain, name, ok := strings.Cut(resourceName, "/")
+	if !ok || domain == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid resource name %q: must be of the form <domain>/<name>", resourceName)
+	}
+
+	if len(domain) > 253 {
+		return fmt.Errorf("invalid resource name %q: domain must be at most 253 characters", resourceName)
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if len(label) > 63 || !dnsLabelRegexp.MatchString(label) {
+			return fmt.Errorf("invalid resource name %q: domain %q must be a lowercase DNS subdomain", resourceName, domain)
+		}
+	}
+	if domain == "kubernetes.io" || strings.HasSuffix(domain, ".kubernetes.io") {
+		return fmt.Errorf("invalid resource name %q: the kubernetes.io domain is reserved", resourceName)
+	}
+
+	if len(name) > 63 || !resourceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid resource name %q: name %q must be at most 63 alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric character",
+			resourceName, name)
+	}
+	return nil
+}
+
+// ValidateDeviceCount 校验设备数量在(0, max]范围内，max不大于零时使用DefaultMaxDeviceCount，
+// 避免误输入的超大数量创建大量设备
+func ValidateDeviceCount(count, max int) error {
+	if max <= 0 {
+		max = DefaultMaxDeviceCount
+	}
+	if count <= 0 {
+		return fmt.Errorf("%w: device count %d must be greater than 0", ErrDeviceInit, count)
+	}
+	if count > max {
+		return fmt.Errorf("%w: device count %d exceeds the maximum of %d", ErrDeviceInit, count, max)
+	}
+	return nil
+}
+
+// Validate 校验插件配置（资源名称、设备数量和设备ID格式），避免注册时被kubelet拒绝
+func (p *PPUDevicePlugin) Validate() error {
+	if err := ValidateResourceName(p.resourceName); err != nil {
+		return err
+	}
+	if err := ValidateDeviceCount(p.DeviceCount(), p.opts.MaxDeviceCount); err != nil {
+		return err
+	}
+	return ValidateDeviceIDFormat(p.opts.DeviceIDFormat)
+}
@@ -0,0 +1,91 @@
+package deviceplugin
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateResourceName 测试扩展资源名称格式校验
+func TestValidateResourceName(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName string
+		wantErr      bool
+	}{
+		{name: "vendor domain", resourceName: "alibabacloud.com/ppu"},
+		{name: "name with dash and dot", resourceName: "test.com/ppu-shared.v2"},
+		{name: "single label domain", resourceName: "example/ppu"},
+		{name: "missing domain", resourceName: "ppu", wantErr: true},
+		{name: "empty domain", resourceName: "/ppu", wantErr: true},
+		{name: "empty name", resourceName: "test.com/", wantErr: true},
+		{name: "extra slash", resourceName: "test.com/ppu/shared", wantErr: true},
+		{name: "uppercase domain", resourceName: "Test.com/ppu", wantErr: true},
+		{name: "domain label ends with dash", resourceName: "test-.com/ppu", wantErr: true},
+		{name: "name starts with dash", resourceName: "test.com/-ppu", wantErr: true},
+		{name: "name with space", resourceName: "test.com/my ppu", wantErr: true},
+		{name: "reserved domain", resourceName: "kubernetes.io/ppu", wantErr: true},
+		{name: "reserved subdomain", resourceName: "node.kubernetes.io/ppu", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResourceName(tt.resourceName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResourceName(%q) error = %v, wantErr %v", tt.resourceName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestStartRejectsInvalidResourceName 测试资源名称非法时Start在注册前失败
+func TestStartRejectsInvalidResourceName(t *testing.T) {
+	tmpDir := t.TempDir()
+	startFakeKubelet(t, tmpDir)
+
+	plugin := NewPPUDevicePlugin("ppu", 1, tmpDir)
+	if err := plugin.Start(); err == nil {
+		plugin.Stop()
+		t.Fatal("Expected Start to reject a resource name without a domain")
+	}
+	if plugin.server != nil {
+		t.Error("Expected the gRPC server not to be started")
+	}
+}
+
+// TestValidateDeviceCount 测试设备数量为零、负数或超过上限时校验失败
+func TestValidateDeviceCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		max     int
+		wantErr bool
+	}{
+		{name: "zero", count: 0, wantErr: true},
+		{name: "negative", count: -1, wantErr: true},
+		{name: "over default max", count: 16000, wantErr: true},
+		{name: "default max", count: DefaultMaxDeviceCount},
+		{name: "over configured max", count: 9, max: 8, wantErr: true},
+		{name: "within configured max", count: 8, max: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceCount(tt.count, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDeviceCount(%d, %d) error = %v, wantErr %v", tt.count, tt.max, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDeviceInit) {
+				t.Errorf("Expected ErrDeviceInit, got %v", err)
+			}
+		})
+	}
+
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 16000, t.TempDir(), Options{})
+	if err := plugin.Start(); err == nil {
+		plugin.Stop()
+		t.Fatal("Expected Start to reject a device count over the maximum")
+	}
+	if count := len(plugin.devices); count != 0 {
+		t.Errorf("Expected no devices to be created, got %d", count)
+	}
+}
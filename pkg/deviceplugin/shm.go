@@ -0,0 +1,70 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// shmPath 共享内存的挂载路径
+	shmPath = "/dev/shm"
+	// hugepagesPathPrefix 大页挂载路径前缀，与kubelet的多页大小约定一致，如/dev/hugepages-2Mi
+	hugepagesPathPrefix = "/dev/hugepages-"
+)
+
+var (
+	// shmSizeRegexp 共享内存大小，如64Mi、1Gi
+	shmSizeRegexp = regexp.MustCompile(`^[1-9][0-9]*(Ki|Mi|Gi|Ti)?$`)
+	// hugepageSizeRegexp 大页大小，如2Mi、1Gi
+	hugepageSizeRegexp = regexp.MustCompile(`^[1-9][0-9]*(Mi|Gi)$`)
+)
+
+// ValidateShmSize 校验共享内存大小格式，空字符串表示不挂载
+func ValidateShmSize(size string) error {
+	if size != "" && !shmSizeRegexp.MatchString(size) {
+		return fmt.Errorf("invalid shm size %q: expected a positive size such as 64Mi or 1Gi", size)
+	}
+	return nil
+}
+
+// ParseHugepages 解析逗号分隔的大页大小列表，如"2Mi,1Gi"
+func ParseHugepages(s string) ([]string, error) {
+	sizes := []string{}
+	for _, size := range strings.Split(s, ",") {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			continue
+		}
+		if !hugepageSizeRegexp.MatchString(size) {
+			return nil, fmt.Errorf("invalid hugepage size %q: expected a size such as 2Mi or 1Gi", size)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// memoryMounts 返回模拟共享内存和大页的挂载，配置的大小通过注解传入容器
+func (p *PPUDevicePlugin) memoryMounts() []*v1beta1.Mount {
+	mounts := []*v1beta1.Mount{}
+	if p.opts.ShmSize != "" {
+		mounts = append(mounts, &v1beta1.Mount{HostPath: shmPath, ContainerPath: shmPath})
+	}
+	for _, size := range p.opts.Hugepages {
+		path := hugepagesPathPrefix + size
+		mounts = append(mounts, &v1beta1.Mount{HostPath: path, ContainerPath: path})
+	}
+	return mounts
+}
+
+// memoryAnnotations 将共享内存大小和大页大小写入分配注解
+func (p *PPUDevicePlugin) memoryAnnotations(annotations map[string]string) {
+	if p.opts.ShmSize != "" {
+		annotations[p.annotationKey("shm-size")] = p.opts.ShmSize
+	}
+	if len(p.opts.Hugepages) > 0 {
+		annotations[p.annotationKey("hugepages")] = strings.Join(p.opts.Hugepages, ",")
+	}
+}
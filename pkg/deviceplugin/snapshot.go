@@ -0,0 +1,136 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// DeviceSnapshot 单个设备在某一时刻的状态
+type DeviceSnapshot struct {
+	ID          string  `json:"id"`
+	Health      string  `json:"health"`
+	NUMANode    *int64  `json:"numaNode,omitempty"`
+	Utilization float64 `json:"utilization"`
+	Temperature float64 `json:"temperature"`
+	Allocated   bool    `json:"allocated"`
+	AllocatedTo string  `json:"allocatedTo,omitempty"`
+	Reserved    bool    `json:"reserved,omitempty"`
+}
+
+// Snapshot 插件在某一时刻的设备状态视图
+type Snapshot struct {
+	Devices []DeviceSnapshot `json:"devices"`
+}
+
+// ShutdownReport 插件停止时输出的运行汇总
+type ShutdownReport struct {
+	TotalAllocations uint64        `json:"totalAllocations"`
+	AllocatedDevices []string      `json:"allocatedDevices"`
+	UnhealthyDevices []string      `json:"unhealthyDevices"`
+	Uptime           time.Duration `json:"uptime"`
+}
+
+// Snapshot 返回按设备ID排序的设备状态视图
+func (p *PPUDevicePlugin) Snapshot() Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	devices := make([]DeviceSnapshot, 0, len(p.devices))
+	for deviceID, device := range p.devices {
+		owner, allocated := p.allocated[deviceID]
+		snapshot := DeviceSnapshot{
+			ID:          deviceID,
+			Health:      device.Health,
+			Utilization: p.utilization[deviceID],
+			Temperature: p.temperature[deviceID],
+			Allocated:   allocated,
+			AllocatedTo: owner,
+			Reserved:    p.reserved[deviceID],
+		}
+		if device.Topology != nil && len(device.Topology.Nodes) > 0 {
+			node := device.Topology.Nodes[0].ID
+			snapshot.NUMANode = &node
+		}
+		devices = append(devices, snapshot)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return deviceIDLess(devices[i].ID, devices[j].ID)
+	})
+
+	return Snapshot{Devices: devices}
+}
+
+// ShutdownReport 根据累计计数和当前设备状态生成运行汇总
+func (p *PPUDevicePlugin) ShutdownReport() ShutdownReport {
+	snapshot := p.Snapshot()
+
+	p.mu.RLock()
+	report := ShutdownReport{
+		TotalAllocations: p.allocationsServed,
+		AllocatedDevices: []string{},
+		UnhealthyDevices: []string{},
+	}
+	if !p.startedAt.IsZero() {
+		report.Uptime = time.Since(p.startedAt)
+	}
+	p.mu.RUnlock()
+
+	for _, device := range snapshot.Devices {
+		if device.AllocatedTo != "" {
+			report.AllocatedDevices = append(report.AllocatedDevices, device.ID)
+		}
+		if device.Health != v1beta1.Healthy {
+			report.UnhealthyDevices = append(report.UnhealthyDevices, device.ID)
+		}
+	}
+
+	return report
+}
+
+// logShutdownReport 以结构化日志输出运行汇总
+func (p *PPUDevicePlugin) logShutdownReport() {
+	report := p.ShutdownReport()
+
+	p.log.WithFields(log.Fields{
+		"totalAllocations": report.TotalAllocations,
+		"allocatedDevices": report.AllocatedDevices,
+		"unhealthyDevices": report.UnhealthyDevices,
+		"uptime":           report.Uptime.String(),
+	}).Info("PPU device plugin shutdown report")
+}
+
+// allocationOwner 生成分配对象标识
+func allocationOwner(allocationID uint64, containerIndex int) string {
+	return fmt.Sprintf("allocation-%d/container-%d", allocationID, containerIndex)
+}
+
+// recordAllocation 为请求中的每个容器记录分配结果
+func (p *PPUDevicePlugin) recordAllocation(allocationID uint64, request *v1beta1.AllocateRequest, err error) {
+	now := time.Now()
+	for i, containerRequest := range request.ContainerRequests {
+		record := AllocationRecord{
+			Time:      now,
+			Container: allocationOwner(allocationID, i),
+			Devices:   append([]string{}, containerRequest.DevicesIDs...),
+			Outcome:   allocationOutcome(err),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		p.history.add(record)
+	}
+}
+
+// nextAllocationID 返回下一次Allocate调用的序号
+func (p *PPUDevicePlugin) nextAllocationID() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.allocationSeq++
+	return p.allocationSeq
+}
@@ -0,0 +1,36 @@
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestShutdownReport 测试运行汇总反映之前的分配和健康状态
+func TestShutdownReport(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{})
+
+	for _, deviceIDs := range [][]string{{"ppu-0"}, {"ppu-1", "ppu-2"}} {
+		_, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+			ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: deviceIDs}},
+		})
+		if err != nil {
+			t.Fatalf("Allocate failed: %v", err)
+		}
+	}
+	plugin.devices["ppu-3"].Health = v1beta1.Unhealthy
+
+	report := plugin.ShutdownReport()
+
+	if report.TotalAllocations != 2 {
+		t.Errorf("Expected 2 allocations, got %d", report.TotalAllocations)
+	}
+	if expected := []string{"ppu-0", "ppu-1", "ppu-2"}; !reflect.DeepEqual(report.AllocatedDevices, expected) {
+		t.Errorf("Expected allocated devices %v, got %v", expected, report.AllocatedDevices)
+	}
+	if expected := []string{"ppu-3"}; !reflect.DeepEqual(report.UnhealthyDevices, expected) {
+		t.Errorf("Expected unhealthy devices %v, got %v", expected, report.UnhealthyDevices)
+	}
+}
@@ -0,0 +1,42 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// defaultSocketMode 默认的插件socket文件权限，仅属主可读写
+	defaultSocketMode os.FileMode = 0600
+	// defaultSocketDirMode 默认创建socket目录时使用的权限
+	defaultSocketDirMode os.FileMode = 0755
+)
+
+// ParseFileMode 解析八进制的文件权限（如0600），超出0777时返回错误
+func ParseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode %q: expected an octal value such as 0600", s)
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q: only permission bits (up to 0777) are allowed", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// socketMode 返回插件socket文件的权限，未配置时使用默认的0600
+func socketMode(opts Options) os.FileMode {
+	if opts.SocketMode == 0 {
+		return defaultSocketMode
+	}
+	return opts.SocketMode
+}
+
+// socketDirMode 返回创建socket目录时使用的权限，未配置时使用默认的0755
+func socketDirMode(opts Options) os.FileMode {
+	if opts.SocketDirMode == 0 {
+		return defaultSocketDirMode
+	}
+	return opts.SocketDirMode
+}
@@ -0,0 +1,54 @@
+package deviceplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSocketMode 测试启动后socket文件和新建的socket目录使用配置的权限
+func TestSocketMode(t *testing.T) {
+	socketDir := filepath.Join(t.TempDir(), "plugins")
+	plugin := NewPPUDevicePluginWithOptions("test.com/ppu", 1, socketDir, Options{
+		NoRegister:    true,
+		SocketMode:    0640,
+		SocketDirMode: 0750,
+	})
+	if err := plugin.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop()
+
+	info, err := os.Stat(plugin.Socket())
+	if err != nil {
+		t.Fatalf("Stat socket failed: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Errorf("Expected socket mode 0640, got %#o", mode)
+	}
+
+	info, err = os.Stat(socketDir)
+	if err != nil {
+		t.Fatalf("Stat socket directory failed: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0750 {
+		t.Errorf("Expected socket directory mode 0750, got %#o", mode)
+	}
+}
+
+// TestParseFileMode 测试解析八进制文件权限
+func TestParseFileMode(t *testing.T) {
+	mode, err := ParseFileMode("0600")
+	if err != nil {
+		t.Fatalf("ParseFileMode failed: %v", err)
+	}
+	if mode != 0600 {
+		t.Errorf("Expected 0600, got %#o", mode)
+	}
+
+	for _, input := range []string{"", "rw", "0800", "01777"} {
+		if _, err := ParseFileMode(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
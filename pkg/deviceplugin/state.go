@@ -0,0 +1,116 @@
+package deviceplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// allocationState 持久化到状态文件的分配状态
+type allocationState struct {
+	// Allocated 已分配设备及其分配对象（deviceID -> owner）
+	Allocated map[string]string `json:"allocated"`
+	// AllocationSeq 最近一次分配的序号，重启后继续递增以避免分配对象重名
+	AllocationSeq uint64 `json:"allocationSeq"`
+}
+
+// saveState 将当前分配状态原子地写入状态文件，未配置状态文件时不做任何事
+func (p *PPUDevicePlugin) saveState() {
+	if p.opts.StateFile == "" {
+		return
+	}
+
+	// 持有persistMu直到写入完成，保证快照按顺序落盘
+	p.persistMu.Lock()
+	defer p.persistMu.Unlock()
+
+	p.mu.RLock()
+	state := allocationState{
+		Allocated:     make(map[string]string, len(p.allocated)),
+		AllocationSeq: p.allocationSeq,
+	}
+	for deviceID, owner := range p.allocated {
+		state.Allocated[deviceID] = owner
+	}
+	p.mu.RUnlock()
+
+	if err := writeStateFile(p.opts.StateFile, state); err != nil {
+		p.log.Warnf("Failed to persist allocation state: %v", err)
+	}
+}
+
+// writeStateFile 先写入临时文件再重命名，避免崩溃时留下不完整的状态文件
+func writeStateFile(path string, state allocationState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp state file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp state file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename state file: %v", err)
+	}
+	return nil
+}
+
+// loadState 从状态文件恢复分配状态，文件不存在或损坏时从空状态开始
+func (p *PPUDevicePlugin) loadState() {
+	if p.opts.StateFile == "" {
+		return
+	}
+
+	data, err := os.ReadFile(p.opts.StateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			p.log.Infof("State file %s not found, starting with no allocations", p.opts.StateFile)
+		} else {
+			p.log.Warnf("Failed to read state file %s, starting with no allocations: %v", p.opts.StateFile, err)
+		}
+		return
+	}
+
+	var state allocationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		p.log.Warnf("State file %s is corrupt, starting with no allocations: %v", p.opts.StateFile, err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	restored := 0
+	for deviceID, owner := range state.Allocated {
+		if _, exists := p.devices[deviceID]; !exists {
+			p.log.Warnf("Dropping persisted allocation of unknown device %s", deviceID)
+			continue
+		}
+		p.allocated[deviceID] = owner
+		p.setUtilizationLocked(deviceID, allocatedUtilization)
+		restored++
+	}
+	p.updateDeviceGaugesLocked()
+	if state.AllocationSeq > p.allocationSeq {
+		p.allocationSeq = state.AllocationSeq
+	}
+
+	p.log.Infof("Restored %d allocations from state file %s", restored, p.opts.StateFile)
+}
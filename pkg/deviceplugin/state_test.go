@@ -0,0 +1,73 @@
+package deviceplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestStateRestoredAfterRestart 测试重启后从状态文件恢复分配
+func TestStateRestoredAfterRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	plugin := newTestPlugin(t, 4, Options{StateFile: stateFile})
+	allocate(t, plugin, "ppu-1", "ppu-2")
+
+	// 模拟重启：使用同一状态文件创建新插件
+	restarted := newTestPlugin(t, 4, Options{StateFile: stateFile})
+	restarted.loadState()
+
+	for _, deviceID := range []string{"ppu-1", "ppu-2"} {
+		if restarted.allocated[deviceID] != "allocation-1/container-0" {
+			t.Errorf("Expected %s to be restored to allocation-1/container-0, got %q", deviceID, restarted.allocated[deviceID])
+		}
+		if got, _ := restarted.DeviceUtilization(deviceID); got != allocatedUtilization {
+			t.Errorf("Expected %s utilization %v, got %v", deviceID, allocatedUtilization, got)
+		}
+	}
+	if _, exists := restarted.allocated["ppu-0"]; exists {
+		t.Error("Expected ppu-0 to remain unallocated")
+	}
+
+	// 恢复后的分配序号继续递增
+	if id := restarted.nextAllocationID(); id != 2 {
+		t.Errorf("Expected next allocation ID 2, got %d", id)
+	}
+}
+
+// TestStateReleasePersisted 测试释放设备后状态文件同步更新
+func TestStateReleasePersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	plugin := newTestPlugin(t, 2, Options{StateFile: stateFile})
+	allocate(t, plugin, "ppu-0")
+	plugin.releaseDevices([]string{"ppu-0"})
+
+	restarted := newTestPlugin(t, 2, Options{StateFile: stateFile})
+	restarted.loadState()
+	if len(restarted.allocated) != 0 {
+		t.Errorf("Expected no allocations after release, got %v", restarted.allocated)
+	}
+}
+
+// TestStateCorruptFile 测试状态文件损坏时从空状态开始
+func TestStateCorruptFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(stateFile, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	plugin := newTestPlugin(t, 2, Options{StateFile: stateFile})
+	plugin.loadState()
+	if len(plugin.allocated) != 0 {
+		t.Errorf("Expected no allocations from a corrupt state file, got %v", plugin.allocated)
+	}
+
+	// 后续写入覆盖损坏的文件
+	allocate(t, plugin, "ppu-0")
+	restarted := newTestPlugin(t, 2, Options{StateFile: stateFile})
+	restarted.loadState()
+	if _, exists := restarted.allocated["ppu-0"]; !exists {
+		t.Error("Expected the new allocation to be persisted over the corrupt file")
+	}
+}
@@ -0,0 +1,400 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// AllocationStrategyPacked 按设备序号从小到大选择设备
+	AllocationStrategyPacked = "packed"
+	// AllocationStrategySpread 将设备轮流分散到各NUMA节点
+	AllocationStrategySpread = "spread"
+	// AllocationStrategyNUMAPacked 尽量将设备集中在同一NUMA节点
+	AllocationStrategyNUMAPacked = "numa-packed"
+	// AllocationStrategyInterconnect 尽量将设备集中在同一互联组内
+	AllocationStrategyInterconnect = "interconnect"
+	// AllocationStrategyTemperatureAware 优先选择温度最低的设备
+	AllocationStrategyTemperatureAware = "temperature-aware"
+)
+
+// AllocationStrategies 支持的首选分配策略
+var AllocationStrategies = []string{
+	AllocationStrategyPacked,
+	AllocationStrategySpread,
+	AllocationStrategyNUMAPacked,
+	AllocationStrategyInterconnect,
+	AllocationStrategyTemperatureAware,
+}
+
+const (
+	// PowerStrategyConcentrate 将分配集中到尽量少的供电域，便于空闲供电域下电
+	PowerStrategyConcentrate = "concentrate"
+	// PowerStrategySpread 将分配分散到尽量多的供电域，便于散热
+	PowerStrategySpread = "spread"
+)
+
+// PowerStrategies 支持的供电域分配策略
+var PowerStrategies = []string{
+	PowerStrategyConcentrate,
+	PowerStrategySpread,
+}
+
+// ParseDeviceGroups 解析设备分组配置，格式为 "ppu-0,ppu-1;ppu-2,ppu-3"
+func ParseDeviceGroups(spec string) ([][]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	groups := [][]string{}
+	for i, part := range strings.Split(spec, ";") {
+		group := []string{}
+		for _, deviceID := range strings.Split(part, ",") {
+			deviceID = strings.TrimSpace(deviceID)
+			if deviceID == "" {
+				continue
+			}
+			if seen[deviceID] {
+				return nil, fmt.Errorf("device %s appears in more than one group", deviceID)
+			}
+			seen[deviceID] = true
+			group = append(group, deviceID)
+		}
+		if len(group) == 0 {
+			return nil, fmt.Errorf("group %d is empty", i)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// selectPacked 默认分配策略：先包含必须包含的设备，再按可用列表顺序补足
+func selectPacked(available, mustInclude []string, size int) []string {
+	selected := append([]string{}, mustInclude...)
+	chosen := make(map[string]bool, len(selected))
+	for _, deviceID := range selected {
+		chosen[deviceID] = true
+	}
+
+	for _, deviceID := range available {
+		if len(selected) >= size {
+			break
+		}
+		if !chosen[deviceID] {
+			selected = append(selected, deviceID)
+			chosen[deviceID] = true
+		}
+	}
+
+	return selected
+}
+
+// selectPreferred 按配置的分配策略名称从注册表中选择策略并计算首选设备，未知名称回退到packed
+func (p *PPUDevicePlugin) selectPreferred(available, mustInclude []string, size int) []string {
+	sorted := append([]string{}, available...)
+	sort.Slice(sorted, func(i, j int) bool { return deviceIDLess(sorted[i], sorted[j]) })
+
+	name := p.opts.AllocationStrategy
+	if name == "" {
+		name = AllocationStrategyPacked
+	}
+	strategy, exists := lookupStrategy(name)
+	if !exists {
+		p.log.Warnf("Unknown allocation strategy %q, falling back to %s", name, AllocationStrategyPacked)
+		strategy, _ = lookupStrategy(AllocationStrategyPacked)
+	}
+
+	if s, ok := strategy.(pluginStrategy); ok {
+		return s.selectFor(p, sorted, mustInclude, size)
+	}
+	return strategy.Select(sorted, mustInclude, size, p.strategyDevices(append(append([]string{}, sorted...), mustInclude...)))
+}
+
+// numaNodes 返回设备所在的NUMA节点，未上报拓扑信息的设备不包含在结果中
+func (p *PPUDevicePlugin) numaNodes(deviceIDs []string) map[string]int64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	nodes := make(map[string]int64, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		device, exists := p.devices[deviceID]
+		if !exists || device.Topology == nil || len(device.Topology.Nodes) == 0 {
+			continue
+		}
+		nodes[deviceID] = device.Topology.Nodes[0].ID
+	}
+	return nodes
+}
+
+// numaNodeSets 返回设备所在的全部NUMA节点，跨节点的设备包含多个节点，未上报拓扑信息的设备不包含在结果中
+func (p *PPUDevicePlugin) numaNodeSets(deviceIDs []string) map[string][]int64 {
+	return topologyNodeSets(p.strategyDevices(deviceIDs))
+}
+
+// topologyNodeSets 从设备的拓扑信息中提取其所在的全部NUMA节点，未上报拓扑信息的设备不包含在结果中
+func topologyNodeSets(devices map[string]*v1beta1.Device) map[string][]int64 {
+	nodes := make(map[string][]int64, len(devices))
+	for deviceID, device := range devices {
+		if device.Topology == nil || len(device.Topology.Nodes) == 0 {
+			continue
+		}
+		ids := make([]int64, 0, len(device.Topology.Nodes))
+		for _, node := range device.Topology.Nodes {
+			ids = append(ids, node.ID)
+		}
+		nodes[deviceID] = ids
+	}
+	return nodes
+}
+
+// singleNodeSets 将每个设备只属于一个分组的映射转换为numaNodeSets的形式
+func singleNodeSets(groups map[string]int64) map[string][]int64 {
+	nodes := make(map[string][]int64, len(groups))
+	for deviceID, group := range groups {
+		nodes[deviceID] = []int64{group}
+	}
+	return nodes
+}
+
+// interconnectGroups 返回设备所在的互联组序号，未归属任何互联组的设备各自视为独立的组
+func (p *PPUDevicePlugin) interconnectGroups(deviceIDs []string) map[string]int64 {
+	return groupIndexes(deviceIDs, p.interconnect, int64(len(p.opts.InterconnectGroups)))
+}
+
+// groupIndexes 按index查找设备所在的组序号，未出现在index中的设备从next开始各自分配独立的组序号
+func groupIndexes(deviceIDs []string, index map[string]int64, next int64) map[string]int64 {
+	groups := make(map[string]int64, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		if _, exists := groups[deviceID]; exists {
+			continue
+		}
+		if group, exists := index[deviceID]; exists {
+			groups[deviceID] = group
+			continue
+		}
+		groups[deviceID] = next
+		next++
+	}
+	return groups
+}
+
+// groupByNUMANode 按NUMA节点对候选设备分组，跨节点的设备出现在其所有节点的分组中，无拓扑信息的设备归入节点-1，
+// 返回分组及升序的节点列表
+func groupByNUMANode(available []string, chosen map[string]bool, nodes map[string][]int64) (map[int64][]string, []int64) {
+	groups := make(map[int64][]string)
+	order := []int64{}
+	for _, deviceID := range available {
+		if chosen[deviceID] {
+			continue
+		}
+		deviceNodes, exists := nodes[deviceID]
+		if !exists {
+			deviceNodes = []int64{-1}
+		}
+		for _, node := range deviceNodes {
+			if _, exists := groups[node]; !exists {
+				order = append(order, node)
+			}
+			groups[node] = append(groups[node], deviceID)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	return groups, order
+}
+
+// pruneChosen 从各分组中移除已选择的设备，跨节点的设备被某个节点选中后不再计入其他节点
+func pruneChosen(groups map[int64][]string, chosen map[string]bool) {
+	for node, deviceIDs := range groups {
+		remaining := deviceIDs[:0:0]
+		for _, deviceID := range deviceIDs {
+			if !chosen[deviceID] {
+				remaining = append(remaining, deviceID)
+			}
+		}
+		groups[node] = remaining
+	}
+}
+
+// selectSpread 在各NUMA节点之间轮流选择设备
+func selectSpread(available, mustInclude []string, size int, nodes map[string][]int64) []string {
+	selected := append([]string{}, mustInclude...)
+	chosen := make(map[string]bool, len(selected))
+	for _, deviceID := range selected {
+		chosen[deviceID] = true
+	}
+
+	groups, order := groupByNUMANode(available, chosen, nodes)
+	for len(selected) < size {
+		progressed := false
+		for _, node := range order {
+			if len(selected) >= size {
+				break
+			}
+			pruneChosen(groups, chosen)
+			if len(groups[node]) == 0 {
+				continue
+			}
+			selected = append(selected, groups[node][0])
+			chosen[groups[node][0]] = true
+			groups[node] = groups[node][1:]
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	return selected
+}
+
+// selectNUMAPacked 优先选择能容纳全部设备的单个NUMA节点（必须包含的设备所在节点优先，其次剩余设备最少的节点），
+// 无法在单个节点内满足时从剩余设备最多的节点依次补足
+func selectNUMAPacked(available, mustInclude []string, size int, nodes map[string][]int64) []string {
+	selected := append([]string{}, mustInclude...)
+	chosen := make(map[string]bool, len(selected))
+	used := make(map[int64]int)
+	for _, deviceID := range selected {
+		chosen[deviceID] = true
+		for _, node := range nodes[deviceID] {
+			used[node]++
+		}
+	}
+
+	groups, order := groupByNUMANode(available, chosen, nodes)
+	need := size - len(selected)
+
+	fits := func(node int64) bool { return len(groups[node]) >= need }
+	better := func(a, b int64) bool {
+		if fits(a) != fits(b) {
+			return fits(a)
+		}
+		if used[a] != used[b] {
+			return used[a] > used[b]
+		}
+		if fits(a) {
+			return len(groups[a]) < len(groups[b])
+		}
+		return len(groups[a]) > len(groups[b])
+	}
+
+	for len(selected) < size {
+		pruneChosen(groups, chosen)
+		best, found := int64(0), false
+		for _, node := range order {
+			if len(groups[node]) == 0 {
+				continue
+			}
+			if !found || better(node, best) {
+				best, found = node, true
+			}
+		}
+		if !found {
+			break
+		}
+
+		take := size - len(selected)
+		if take > len(groups[best]) {
+			take = len(groups[best])
+		}
+		for _, deviceID := range groups[best][:take] {
+			selected = append(selected, deviceID)
+			chosen[deviceID] = true
+		}
+		groups[best] = groups[best][take:]
+		used[best] += take
+		need = size - len(selected)
+	}
+
+	return selected
+}
+
+// selectByPowerDomain 按供电域选择设备，未归属任何供电域的设备视为独立的供电域
+func selectByPowerDomain(available, mustInclude []string, size int, domains [][]string, strategy string) []string {
+	domainOf := make(map[string]int)
+	for i, domain := range domains {
+		for _, deviceID := range domain {
+			domainOf[deviceID] = i
+		}
+	}
+	nextDomain := len(domains)
+	domainIndex := func(deviceID string) int {
+		if d, exists := domainOf[deviceID]; exists {
+			return d
+		}
+		domainOf[deviceID] = nextDomain
+		nextDomain++
+		return domainOf[deviceID]
+	}
+
+	selected := append([]string{}, mustInclude...)
+	chosen := make(map[string]bool, len(selected))
+	used := make(map[int]int)
+	for _, deviceID := range selected {
+		chosen[deviceID] = true
+		used[domainIndex(deviceID)]++
+	}
+
+	// 按供电域对候选设备分组，保持可用列表中的顺序
+	candidates := make(map[int][]string)
+	order := []int{}
+	for _, deviceID := range available {
+		if chosen[deviceID] {
+			continue
+		}
+		d := domainIndex(deviceID)
+		if _, exists := candidates[d]; !exists {
+			order = append(order, d)
+		}
+		candidates[d] = append(candidates[d], deviceID)
+	}
+
+	for len(selected) < size {
+		best := -1
+		for _, d := range order {
+			if len(candidates[d]) == 0 {
+				continue
+			}
+			if best == -1 || betterPowerDomain(strategy, d, best, used, candidates) {
+				best = d
+			}
+		}
+		if best == -1 {
+			break
+		}
+
+		selected = append(selected, candidates[best][0])
+		candidates[best] = candidates[best][1:]
+		used[best]++
+	}
+
+	return selected
+}
+
+// betterPowerDomain 判断供电域a是否比b更适合下一次选择
+func betterPowerDomain(strategy string, a, b int, used map[int]int, candidates map[int][]string) bool {
+	if strategy == PowerStrategySpread {
+		return used[a] < used[b]
+	}
+
+	// concentrate：优先已使用的供电域，其次剩余设备更多的供电域
+	if used[a] != used[b] {
+		return used[a] > used[b]
+	}
+	return len(candidates[a]) > len(candidates[b])
+}
+
+// interconnectIndex 将互联组配置转换为设备到组序号的映射
+func interconnectIndex(groups [][]string) map[string]int64 {
+	index := make(map[string]int64)
+	for i, group := range groups {
+		for _, deviceID := range group {
+			index[deviceID] = int64(i)
+		}
+	}
+	return index
+}
@@ -0,0 +1,315 @@
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestSelectByPowerDomain 测试按供电域集中或分散选择设备
+func TestSelectByPowerDomain(t *testing.T) {
+	domains := [][]string{
+		{"ppu-0", "ppu-1", "ppu-2", "ppu-3"},
+		{"ppu-4", "ppu-5", "ppu-6", "ppu-7"},
+		{"ppu-8", "ppu-9", "ppu-10", "ppu-11"},
+	}
+	available := []string{"ppu-0", "ppu-4", "ppu-5", "ppu-6", "ppu-8", "ppu-9", "ppu-10", "ppu-11"}
+
+	tests := []struct {
+		name        string
+		strategy    string
+		mustInclude []string
+		size        int
+		expected    []string
+	}{
+		{
+			name:     "ConcentrateFillsLargestDomain",
+			strategy: PowerStrategyConcentrate,
+			size:     4,
+			expected: []string{"ppu-8", "ppu-9", "ppu-10", "ppu-11"},
+		},
+		{
+			name:        "ConcentrateStaysInMustIncludeDomain",
+			strategy:    PowerStrategyConcentrate,
+			mustInclude: []string{"ppu-4"},
+			size:        3,
+			expected:    []string{"ppu-4", "ppu-5", "ppu-6"},
+		},
+		{
+			name:     "SpreadUsesOneDevicePerDomain",
+			strategy: PowerStrategySpread,
+			size:     3,
+			expected: []string{"ppu-0", "ppu-4", "ppu-8"},
+		},
+		{
+			name:     "SpreadWrapsAround",
+			strategy: PowerStrategySpread,
+			size:     5,
+			expected: []string{"ppu-0", "ppu-4", "ppu-8", "ppu-5", "ppu-9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectByPowerDomain(available, tt.mustInclude, tt.size, domains, tt.strategy)
+			if !reflect.DeepEqual(selected, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, selected)
+			}
+		})
+	}
+}
+
+// TestPackedStrategyPowerDomains 测试配置供电域策略时packed策略按供电域选择首选设备
+func TestPackedStrategyPowerDomains(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{
+		PowerDomains:  [][]string{{"ppu-0", "ppu-1"}, {"ppu-2", "ppu-3"}},
+		PowerStrategy: PowerStrategySpread,
+	})
+	selected := preferredAllocation(t, plugin, []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}, nil, 2)
+	if expected := []string{"ppu-0", "ppu-2"}; !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected one device per power domain %v, got %v", expected, selected)
+	}
+}
+
+// TestParseDeviceGroups 测试设备分组配置解析
+func TestParseDeviceGroups(t *testing.T) {
+	groups, err := ParseDeviceGroups("ppu-0,ppu-1; ppu-2,ppu-3")
+	if err != nil {
+		t.Fatalf("ParseDeviceGroups failed: %v", err)
+	}
+	expected := [][]string{{"ppu-0", "ppu-1"}, {"ppu-2", "ppu-3"}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+
+	for _, spec := range []string{"ppu-0;;ppu-1", "ppu-0,ppu-1;ppu-1"} {
+		if _, err := ParseDeviceGroups(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+// preferredAllocation 调用GetPreferredAllocation并返回首个容器的首选设备
+func preferredAllocation(t *testing.T, plugin *PPUDevicePlugin, available, mustInclude []string, size int32) []string {
+	t.Helper()
+
+	response, err := plugin.GetPreferredAllocation(context.Background(), &v1beta1.PreferredAllocationRequest{
+		ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs:   available,
+			MustIncludeDeviceIDs: mustInclude,
+			AllocationSize:       size,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("GetPreferredAllocation failed: %v", err)
+	}
+	return response.ContainerResponses[0].DeviceIDs
+}
+
+// TestAllocationStrategies 测试8个设备分布在2个NUMA节点时各分配策略的选择结果
+func TestAllocationStrategies(t *testing.T) {
+	available := []string{"ppu-7", "ppu-6", "ppu-5", "ppu-4", "ppu-3", "ppu-2", "ppu-1", "ppu-0"}
+
+	tests := []struct {
+		name        string
+		strategy    string
+		available   []string
+		mustInclude []string
+		size        int32
+		expected    []string
+	}{
+		{
+			name:      "PackedLowestIndexFirst",
+			strategy:  AllocationStrategyPacked,
+			available: available,
+			expected:  []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"},
+		},
+		{
+			name:      "SpreadAlternatesNodes",
+			strategy:  AllocationStrategySpread,
+			available: []string{"ppu-0", "ppu-2", "ppu-4", "ppu-1"},
+			expected:  []string{"ppu-0", "ppu-1", "ppu-2", "ppu-4"},
+		},
+		{
+			name:      "NUMAPackedSingleNode",
+			strategy:  AllocationStrategyNUMAPacked,
+			available: available,
+			expected:  []string{"ppu-0", "ppu-2", "ppu-4", "ppu-6"},
+		},
+		{
+			name:        "NUMAPackedFollowsMustInclude",
+			strategy:    AllocationStrategyNUMAPacked,
+			available:   available,
+			mustInclude: []string{"ppu-3"},
+			expected:    []string{"ppu-3", "ppu-1", "ppu-5", "ppu-7"},
+		},
+		{
+			name:      "NUMAPackedBestFit",
+			strategy:  AllocationStrategyNUMAPacked,
+			available: []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5", "ppu-6"},
+			size:      3,
+			expected:  []string{"ppu-1", "ppu-3", "ppu-5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, 8, Options{NUMANodes: 2, AllocationStrategy: tt.strategy})
+
+			size := tt.size
+			if size == 0 {
+				size = 4
+			}
+			selected := preferredAllocation(t, plugin, tt.available, tt.mustInclude, size)
+			if !reflect.DeepEqual(selected, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, selected)
+			}
+		})
+	}
+}
+
+// TestNUMAPackedSameNode 测试numa-packed在8个设备、2个NUMA节点上分配4个设备时全部位于同一节点
+func TestNUMAPackedSameNode(t *testing.T) {
+	plugin := newTestPlugin(t, 8, Options{NUMANodes: 2, AllocationStrategy: AllocationStrategyNUMAPacked})
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5", "ppu-6", "ppu-7"}
+
+	selected := preferredAllocation(t, plugin, available, nil, 4)
+	if len(selected) != 4 {
+		t.Fatalf("Expected 4 devices, got %v", selected)
+	}
+
+	nodes := plugin.numaNodes(selected)
+	for _, deviceID := range selected {
+		if nodes[deviceID] != nodes[selected[0]] {
+			t.Errorf("Expected all devices on NUMA node %d, %s is on %d", nodes[selected[0]], deviceID, nodes[deviceID])
+		}
+	}
+}
+
+// TestPreferredNUMANode 测试首选NUMA节点设备充足时全部从该节点选择，不足时先选完该节点再从其他节点补足
+func TestPreferredNUMANode(t *testing.T) {
+	node := int64(0)
+	plugin := newTestPlugin(t, 8, Options{NUMANodes: 2, PreferredNUMANode: &node})
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5", "ppu-6", "ppu-7"}
+
+	if selected := preferredAllocation(t, plugin, available, nil, 3); !reflect.DeepEqual(selected, []string{"ppu-0", "ppu-2", "ppu-4"}) {
+		t.Errorf("Expected devices on the default NUMA node 0, got %v", selected)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(NUMAPreferenceMetadataKey, "1"))
+	response, err := plugin.GetPreferredAllocation(ctx, &v1beta1.PreferredAllocationRequest{
+		ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs: []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4"},
+			AllocationSize:     3,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("GetPreferredAllocation failed: %v", err)
+	}
+	// 节点1只有ppu-1和ppu-3可用，剩余一个设备从节点0补足
+	if selected := response.ContainerResponses[0].DeviceIDs; !reflect.DeepEqual(selected, []string{"ppu-1", "ppu-3", "ppu-0"}) {
+		t.Errorf("Expected NUMA node 1 devices followed by a spilled device, got %v", selected)
+	}
+}
+
+// TestMultiNUMADevice 测试跨NUMA的设备上报两个节点，并可在任一节点的亲和性下被选择
+func TestMultiNUMADevice(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{NUMANodes: 2, MultiNUMADevices: []string{"ppu-1"}, AllocationStrategy: AllocationStrategyNUMAPacked})
+
+	nodes := []int64{}
+	for _, node := range plugin.devices["ppu-1"].Topology.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+	if !reflect.DeepEqual(nodes, []int64{1, 0}) {
+		t.Fatalf("Expected ppu-1 on NUMA nodes [1 0], got %v", nodes)
+	}
+	if count := len(plugin.devices["ppu-0"].Topology.Nodes); count != 1 {
+		t.Errorf("Expected ppu-0 on a single NUMA node, got %d", count)
+	}
+
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3"}
+	// 与节点1上的ppu-3一起分配时，ppu-1按节点1选择
+	if selected := preferredAllocation(t, plugin, available[1:], []string{"ppu-3"}, 2); !reflect.DeepEqual(selected, []string{"ppu-3", "ppu-1"}) {
+		t.Errorf("Expected ppu-1 selected with node 1 affinity, got %v", selected)
+	}
+	// 与节点0上的ppu-0一起分配时，ppu-1按节点0选择
+	if selected := preferredAllocation(t, plugin, []string{"ppu-0", "ppu-1", "ppu-3"}, []string{"ppu-0"}, 2); !reflect.DeepEqual(selected, []string{"ppu-0", "ppu-1"}) {
+		t.Errorf("Expected ppu-1 selected with node 0 affinity, got %v", selected)
+	}
+
+	for _, node := range []int64{0, 1} {
+		preferred := node
+		plugin.opts.PreferredNUMANode = &preferred
+		selected := preferredAllocation(t, plugin, available, nil, 2)
+		if !contains(selected, "ppu-1") {
+			t.Errorf("Expected ppu-1 selectable under NUMA node %d preference, got %v", node, selected)
+		}
+	}
+}
+
+// TestInterconnectStrategy 测试interconnect策略将首选分配集中在同一互联组内
+func TestInterconnectStrategy(t *testing.T) {
+	plugin := newTestPlugin(t, 6, Options{
+		AllocationStrategy: AllocationStrategyInterconnect,
+		InterconnectGroups: [][]string{{"ppu-0", "ppu-2", "ppu-4"}, {"ppu-1", "ppu-3"}},
+	})
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4", "ppu-5"}
+
+	tests := []struct {
+		name        string
+		mustInclude []string
+		size        int32
+		expected    []string
+	}{
+		{name: "tightest group", size: 2, expected: []string{"ppu-1", "ppu-3"}},
+		{name: "must include", mustInclude: []string{"ppu-2"}, size: 2, expected: []string{"ppu-2", "ppu-0"}},
+		{name: "larger group", size: 3, expected: []string{"ppu-0", "ppu-2", "ppu-4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := preferredAllocation(t, plugin, available, tt.mustInclude, tt.size)
+			if !reflect.DeepEqual(selected, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, selected)
+			}
+		})
+	}
+}
+
+// TestTemperatureAwareStrategy 测试temperature-aware策略优先选择温度最低的设备
+func TestTemperatureAwareStrategy(t *testing.T) {
+	plugin := newTestPlugin(t, 5, Options{AllocationStrategy: AllocationStrategyTemperatureAware})
+	temperatures := map[string]float64{"ppu-0": 70, "ppu-1": 42, "ppu-2": 65, "ppu-3": 38, "ppu-4": 51}
+	for deviceID, celsius := range temperatures {
+		if err := plugin.SetDeviceTemperature(deviceID, celsius); err != nil {
+			t.Fatalf("SetDeviceTemperature(%s) failed: %v", deviceID, err)
+		}
+	}
+	available := []string{"ppu-0", "ppu-1", "ppu-2", "ppu-3", "ppu-4"}
+
+	tests := []struct {
+		name        string
+		mustInclude []string
+		size        int32
+		expected    []string
+	}{
+		{name: "coolest devices", size: 3, expected: []string{"ppu-3", "ppu-1", "ppu-4"}},
+		{name: "must include", mustInclude: []string{"ppu-0"}, size: 2, expected: []string{"ppu-0", "ppu-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := preferredAllocation(t, plugin, available, tt.mustInclude, tt.size)
+			if !reflect.DeepEqual(selected, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, selected)
+			}
+		})
+	}
+
+	if err := plugin.SetDeviceTemperature("ppu-9", 40); err == nil {
+		t.Error("Expected error for unknown device")
+	}
+}
@@ -0,0 +1,82 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// minInitialTemperature 设备初始模拟温度的下限（摄氏度）
+	minInitialTemperature = 35.0
+	// maxInitialTemperature 设备初始模拟温度的上限（摄氏度）
+	maxInitialTemperature = 55.0
+	// minTemperature 模拟温度漂移的下限（摄氏度）
+	minTemperature = 30.0
+	// maxTemperature 模拟温度漂移的上限（摄氏度）
+	maxTemperature = 90.0
+	// temperatureDrift 每个健康检查周期温度的最大变化幅度（摄氏度）
+	temperatureDrift = 1.0
+)
+
+// SetDeviceTemperature 手动设置设备的模拟温度（摄氏度）
+func (p *PPUDevicePlugin) SetDeviceTemperature(deviceID string, celsius float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.devices[deviceID]; !exists {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	p.temperature[deviceID] = celsius
+	return nil
+}
+
+// DeviceTemperature 返回设备当前的模拟温度
+func (p *PPUDevicePlugin) DeviceTemperature(deviceID string) (float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	celsius, exists := p.temperature[deviceID]
+	return celsius, exists
+}
+
+// initTemperatureLocked 在初始温度范围内随机设置设备温度，调用方需持有p.mu
+func (p *PPUDevicePlugin) initTemperatureLocked(deviceID string) {
+	p.temperature[deviceID] = minInitialTemperature + p.rng.Float64()*(maxInitialTemperature-minInitialTemperature)
+}
+
+// driftTemperatures 使所有设备的温度随机小幅变化，并限制在模拟范围内
+func (p *PPUDevicePlugin) driftTemperatures() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 按固定顺序抽取随机数，保证相同种子下温度变化可复现
+	ids := make([]string, 0, len(p.temperature))
+	for deviceID := range p.temperature {
+		ids = append(ids, deviceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return deviceIDLess(ids[i], ids[j]) })
+
+	for _, deviceID := range ids {
+		celsius := p.temperature[deviceID] + (p.rng.Float64()*2-1)*temperatureDrift
+		if celsius < minTemperature {
+			celsius = minTemperature
+		}
+		if celsius > maxTemperature {
+			celsius = maxTemperature
+		}
+		p.temperature[deviceID] = celsius
+	}
+}
+
+// sortByTemperature 按温度从低到高对设备排序，温度相同时保持原有顺序
+func (p *PPUDevicePlugin) sortByTemperature(deviceIDs []string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sorted := append([]string{}, deviceIDs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return p.temperature[sorted[i]] < p.temperature[sorted[j]]
+	})
+	return sorted
+}
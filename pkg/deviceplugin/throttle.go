@@ -0,0 +1,70 @@
+package deviceplugin
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+// listAndWatchClient 返回ListAndWatch调用方的标识，无法获取时返回unknown
+func listAndWatchClient(ctx context.Context) string {
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil && pr.Addr.String() != "" {
+		return pr.Addr.String()
+	}
+	return "unknown"
+}
+
+// throttleListAndWatch 限制同一客户端重连ListAndWatch的频率，过快的重连在接受前被延迟
+func (p *PPUDevicePlugin) throttleListAndWatch(ctx context.Context) error {
+	interval := p.opts.ListAndWatchMinInterval
+	if interval <= 0 {
+		return nil
+	}
+
+	client := listAndWatchClient(ctx)
+	now := time.Now()
+
+	p.mu.Lock()
+	var delay time.Duration
+	if last, ok := p.lastListAndWatch[client]; ok {
+		delay = interval - now.Sub(last)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	// 预先记录本次连接被接受的时间，使并发重连依次排队
+	p.lastListAndWatch[client] = now.Add(delay)
+	p.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	p.log.Warnf("ListAndWatch client %s reconnecting too fast, throttling for %s", client, delay)
+	return sleepContext(ctx, delay)
+}
+
+// delayInitialList 发送首次设备列表前等待ListAndWatchInitialDelay，模拟启动缓慢的插件，
+// 插件停止或客户端断开时返回false
+func (p *PPUDevicePlugin) delayInitialList(ctx context.Context) bool {
+	delay := p.opts.ListAndWatchInitialDelay
+	if delay <= 0 {
+		return true
+	}
+
+	p.log.Infof("Delaying initial device list by %s", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		p.log.Infof("ListAndWatch client disconnected before the initial device list: %v", ctx.Err())
+		return false
+	case <-p.stop:
+		p.log.Info("ListAndWatch stopped before the initial device list")
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package deviceplugin
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+// TestListAndWatchReconnectThrottle 测试同一客户端快速重连时被延迟
+func TestListAndWatchReconnectThrottle(t *testing.T) {
+	interval := 50 * time.Millisecond
+	plugin := newTestPlugin(t, 2, Options{ListAndWatchMinInterval: interval})
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.UnixAddr{Name: "kubelet", Net: "unix"},
+	})
+	other := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.UnixAddr{Name: "other", Net: "unix"},
+	})
+
+	start := time.Now()
+	if err := plugin.throttleListAndWatch(ctx); err != nil {
+		t.Fatalf("First connection failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("Expected first connection to be accepted immediately, took %s", elapsed)
+	}
+
+	for i := 0; i < 2; i++ {
+		start = time.Now()
+		if err := plugin.throttleListAndWatch(ctx); err != nil {
+			t.Fatalf("Reconnect %d failed: %v", i+1, err)
+		}
+		if elapsed := time.Since(start); elapsed < interval-10*time.Millisecond {
+			t.Errorf("Expected reconnect %d to be throttled for about %s, took %s", i+1, interval, elapsed)
+		}
+	}
+
+	start = time.Now()
+	if err := plugin.throttleListAndWatch(other); err != nil {
+		t.Fatalf("Other client connection failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("Expected a different client not to be throttled, took %s", elapsed)
+	}
+}
+
+// TestListAndWatchThrottleCanceled 测试限流等待期间上下文取消时立即返回
+func TestListAndWatchThrottleCanceled(t *testing.T) {
+	plugin := newTestPlugin(t, 2, Options{ListAndWatchMinInterval: time.Minute})
+
+	if err := plugin.throttleListAndWatch(context.Background()); err != nil {
+		t.Fatalf("First connection failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := plugin.throttleListAndWatch(ctx); err == nil {
+		t.Error("Expected throttled reconnect to fail when the context is canceled")
+	}
+}
@@ -0,0 +1,98 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// WriteTopologyDOT 将模拟设备拓扑（NUMA节点、分区、供电域）输出为Graphviz DOT格式
+func (p *PPUDevicePlugin) WriteTopologyDOT(w io.Writer) error {
+	p.mu.RLock()
+	devices := make([]*v1beta1.Device, 0, len(p.devices))
+	parents := make(map[string]string, len(p.parents))
+	for _, device := range p.devices {
+		devices = append(devices, copyDevice(device))
+	}
+	for partitionID, parentID := range p.parents {
+		parents[partitionID] = parentID
+	}
+	p.mu.RUnlock()
+	sort.Slice(devices, func(i, j int) bool { return deviceIDLess(devices[i].ID, devices[j].ID) })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph %q {\n", p.resourceName)
+	b.WriteString("  node [shape=box];\n")
+
+	numaNodes := []int64{}
+	numaDevices := make(map[int64][]string)
+	parentShown := make(map[string]bool)
+	linked := make(map[string]bool)
+	for _, device := range devices {
+		color := "green"
+		if device.Health != v1beta1.Healthy {
+			color = "red"
+		}
+		fmt.Fprintf(&b, "  %q [color=%s];\n", device.ID, color)
+
+		// 分区挂在所属物理设备下，由物理设备连接NUMA节点
+		member := device.ID
+		if parentID, ok := parents[device.ID]; ok {
+			if !parentShown[parentID] {
+				fmt.Fprintf(&b, "  %q [shape=box3d];\n", parentID)
+				parentShown[parentID] = true
+			}
+			fmt.Fprintf(&b, "  %q -- %q;\n", parentID, device.ID)
+			member = parentID
+		}
+
+		if device.Topology == nil || linked[member] {
+			continue
+		}
+		linked[member] = true
+		for _, node := range device.Topology.Nodes {
+			if _, ok := numaDevices[node.ID]; !ok {
+				numaNodes = append(numaNodes, node.ID)
+			}
+			numaDevices[node.ID] = append(numaDevices[node.ID], member)
+		}
+	}
+
+	sort.Slice(numaNodes, func(i, j int) bool { return numaNodes[i] < numaNodes[j] })
+	for _, node := range numaNodes {
+		name := fmt.Sprintf("numa-%d", node)
+		fmt.Fprintf(&b, "  %q [shape=ellipse];\n", name)
+		for _, deviceID := range numaDevices[node] {
+			fmt.Fprintf(&b, "  %q -- %q;\n", name, deviceID)
+		}
+	}
+
+	for i, domain := range p.opts.PowerDomains {
+		name := fmt.Sprintf("power-domain-%d", i)
+		fmt.Fprintf(&b, "  %q [shape=diamond];\n", name)
+		for _, deviceID := range domain {
+			fmt.Fprintf(&b, "  %q -- %q [style=dashed];\n", name, deviceID)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// handleTopologyDOT 以DOT格式导出设备拓扑
+func (p *PPUDevicePlugin) handleTopologyDOT(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	if err := p.WriteTopologyDOT(w); err != nil {
+		p.log.Warnf("Failed to write topology DOT: %v", err)
+	}
+}
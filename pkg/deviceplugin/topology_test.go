@@ -0,0 +1,47 @@
+package deviceplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestTopologyDOT 测试DOT输出包含设备、NUMA节点和供电域
+func TestTopologyDOT(t *testing.T) {
+	plugin := newTestPlugin(t, 4, Options{
+		EnableAdminAPI: true,
+		NUMANodes:      2,
+		PowerDomains:   [][]string{{"ppu-0", "ppu-1"}, {"ppu-2", "ppu-3"}},
+	})
+	plugin.devices["ppu-3"].Health = "Unhealthy"
+
+	req := httptest.NewRequest(http.MethodGet, "/topology.dot", nil)
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	dot := rec.Body.String()
+	for _, want := range []string{
+		`graph "test.com/ppu" {`,
+		`"ppu-0" [color=green];`,
+		`"ppu-3" [color=red];`,
+		`"numa-0" [shape=ellipse];`,
+		`"numa-0" -- "ppu-0";`,
+		`"numa-0" -- "ppu-2";`,
+		`"numa-1" -- "ppu-1";`,
+		`"numa-1" -- "ppu-3";`,
+		`"power-domain-0" -- "ppu-1" [style=dashed];`,
+		`"power-domain-1" -- "ppu-2" [style=dashed];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %s, got:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, `"numa-0" -- "ppu-1"`) {
+		t.Errorf("Did not expect ppu-1 on NUMA node 0:\n%s", dot)
+	}
+}
@@ -0,0 +1,74 @@
+package deviceplugin
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// maxAllocationReapInterval 过期分配回收的最长检查周期
+	maxAllocationReapInterval = time.Second
+	// minAllocationReapInterval 过期分配回收的最短检查周期
+	minAllocationReapInterval = 10 * time.Millisecond
+)
+
+// allocationReapInterval 根据AllocationTTL计算回收检查周期：TTL的一半，限制在[10ms, 1s]内
+func allocationReapInterval(ttl time.Duration) time.Duration {
+	interval := ttl / 2
+	if interval > maxAllocationReapInterval {
+		return maxAllocationReapInterval
+	}
+	if interval < minAllocationReapInterval {
+		return minAllocationReapInterval
+	}
+	return interval
+}
+
+// markBusyLocked 记录设备的忙碌截止时间，未配置AllocationTTL时不记录，调用方需持有p.mu写锁
+func (p *PPUDevicePlugin) markBusyLocked(deviceID string, now time.Time) {
+	if p.opts.AllocationTTL <= 0 {
+		return
+	}
+	p.busyUntil[deviceID] = now.Add(p.opts.AllocationTTL)
+}
+
+// startAllocationReaper 配置了AllocationTTL时启动后台回收过期分配的goroutine，插件停止时退出
+func (p *PPUDevicePlugin) startAllocationReaper() {
+	if p.opts.AllocationTTL <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(allocationReapInterval(p.opts.AllocationTTL))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				p.reapExpiredAllocations(now)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// reapExpiredAllocations 释放忙碌截止时间早于now的设备，返回被释放的设备ID
+func (p *PPUDevicePlugin) reapExpiredAllocations(now time.Time) []string {
+	p.mu.RLock()
+	var expired []string
+	for deviceID, until := range p.busyUntil {
+		if !now.Before(until) {
+			expired = append(expired, deviceID)
+		}
+	}
+	p.mu.RUnlock()
+
+	if len(expired) == 0 {
+		return nil
+	}
+	sort.Strings(expired)
+	p.log.Infof("Allocation TTL expired for devices %v", expired)
+	p.releaseDevices(expired)
+	return expired
+}
@@ -0,0 +1,41 @@
+package deviceplugin
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestAllocationTTL 测试分配后的设备在TTL内不参与首选分配，TTL到期后被自动释放并重新可用
+func TestAllocationTTL(t *testing.T) {
+	ttl := 100 * time.Millisecond
+	plugin := newTestPlugin(t, 2, Options{AllocationTTL: ttl})
+	plugin.startAllocationReaper()
+	t.Cleanup(func() { close(plugin.stop) })
+
+	available := []string{"ppu-0", "ppu-1"}
+	allocate(t, plugin, "ppu-0")
+
+	if ids := preferredAllocation(t, plugin, available, nil, 2); !reflect.DeepEqual(ids, []string{"ppu-1"}) {
+		t.Errorf("Expected busy ppu-0 to be excluded, got %v", ids)
+	}
+
+	time.Sleep(ttl)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		plugin.mu.RLock()
+		_, busy := plugin.allocated["ppu-0"]
+		plugin.mu.RUnlock()
+		if !busy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for ppu-0 allocation to expire")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if ids := preferredAllocation(t, plugin, available, nil, 2); !reflect.DeepEqual(ids, available) {
+		t.Errorf("Expected ppu-0 to be available again after TTL, got %v", ids)
+	}
+}
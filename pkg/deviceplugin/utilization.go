@@ -0,0 +1,58 @@
+package deviceplugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// allocatedUtilization 已分配设备的模拟利用率（百分比）
+	allocatedUtilization = 90.0
+	// idleUtilization 空闲设备的模拟利用率（百分比）
+	idleUtilization = 5.0
+)
+
+// SetDeviceUtilization 手动设置设备的模拟利用率（0-100）
+func (p *PPUDevicePlugin) SetDeviceUtilization(deviceID string, percent float64) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("utilization %v out of range [0, 100]", percent)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.devices[deviceID]; !exists {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	p.setUtilizationLocked(deviceID, percent)
+	return nil
+}
+
+// DeviceUtilization 返回设备当前的模拟利用率
+func (p *PPUDevicePlugin) DeviceUtilization(deviceID string) (float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	percent, exists := p.utilization[deviceID]
+	return percent, exists
+}
+
+// setUtilizationLocked 更新利用率及对应指标，调用方需持有p.mu
+func (p *PPUDevicePlugin) setUtilizationLocked(deviceID string, percent float64) {
+	p.utilization[deviceID] = percent
+	p.metrics.deviceUtilization.WithLabelValues(deviceID).Set(percent)
+}
+
+// utilizationAnnotation 生成设备利用率注解值，格式为 id=percent,...
+func (p *PPUDevicePlugin) utilizationAnnotation(deviceIDs []string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	parts := make([]string, 0, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		parts = append(parts, deviceID+"="+strconv.FormatFloat(p.utilization[deviceID], 'f', -1, 64))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,53 @@
+package deviceplugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// TestDeviceUtilization 测试利用率随分配和手动设置变化
+func TestDeviceUtilization(t *testing.T) {
+	plugin := NewPPUDevicePlugin("test.com/ppu", 2, t.TempDir())
+	if err := plugin.initDevices(); err != nil {
+		t.Fatalf("initDevices failed: %v", err)
+	}
+
+	response, err := plugin.Allocate(context.Background(), &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIDs: []string{"ppu-0"}}},
+	})
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	if got := testutil.ToFloat64(plugin.metrics.deviceUtilization.WithLabelValues("ppu-0")); got != allocatedUtilization {
+		t.Errorf("Expected allocated device utilization %v, got %v", allocatedUtilization, got)
+	}
+	if got := testutil.ToFloat64(plugin.metrics.deviceUtilization.WithLabelValues("ppu-1")); got != idleUtilization {
+		t.Errorf("Expected idle device utilization %v, got %v", idleUtilization, got)
+	}
+	if got := response.ContainerResponses[0].Annotations["ppu.alibabacloud.com/utilization"]; got != "ppu-0=90" {
+		t.Errorf("Expected utilization annotation 'ppu-0=90', got '%s'", got)
+	}
+
+	if err := plugin.SetDeviceUtilization("ppu-1", 42); err != nil {
+		t.Fatalf("SetDeviceUtilization failed: %v", err)
+	}
+	if err := plugin.SetDeviceUtilization("ppu-9", 42); err == nil {
+		t.Error("Expected error for unknown device")
+	}
+	if err := plugin.SetDeviceUtilization("ppu-1", 120); err == nil {
+		t.Error("Expected error for out of range utilization")
+	}
+
+	rec := httptest.NewRecorder()
+	plugin.adminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `ppu_device_utilization{device_id="ppu-1"} 42`) {
+		t.Errorf("Expected metrics to report manual utilization, got:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,127 @@
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// kubeletSocketDebounce kubelet.sock创建及插件socket删除事件的去抖窗口，避免短时间内重复注册
+const kubeletSocketDebounce = 500 * time.Millisecond
+
+// startKubeletWatcher 监听socket目录，kubelet重启重建kubelet.sock或插件自身的socket被删除后自动恢复服务并重新注册
+func (p *PPUDevicePlugin) startKubeletWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create socket watcher: %v", err)
+	}
+
+	if err := watcher.Add(p.socketPath); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch socket path %s: %v", p.socketPath, err)
+	}
+
+	go p.watchKubeletSocket(watcher)
+	return nil
+}
+
+// watchKubeletSocket 处理socket目录事件，直到插件停止
+func (p *PPUDevicePlugin) watchKubeletSocket(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+
+	var debounce *time.Timer
+	var debounceC <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			switch {
+			case p.pluginSocketRemoved(event):
+				p.log.Debugf("Detected plugin socket removal: %s", event.Name)
+			case filepath.Base(event.Name) == KubeletSocket && event.Has(fsnotify.Create):
+				p.log.Debugf("Detected kubelet socket creation: %s", event.Name)
+			default:
+				continue
+			}
+
+			if debounce == nil {
+				debounce = time.NewTimer(kubeletSocketDebounce)
+			} else {
+				debounce.Reset(kubeletSocketDebounce)
+			}
+			debounceC = debounce.C
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			p.log.Warnf("Socket watcher error: %v", err)
+
+		case <-debounceC:
+			debounceC = nil
+			p.log.Info("Socket directory changed, re-registering device plugin")
+			if err := p.handleKubeletRestart(); err != nil {
+				p.log.Errorf("Failed to re-register after kubelet restart: %v", err)
+			}
+			// 整个目录被删除时原有监听随之失效，目录重建后重新监听
+			if err := watcher.Add(p.socketPath); err != nil {
+				p.log.Warnf("Failed to re-watch socket path %s: %v", p.socketPath, err)
+			}
+
+		case <-p.stop:
+			if debounce != nil {
+				debounce.Stop()
+			}
+			p.log.Debug("Socket watcher stopped")
+			return
+		}
+	}
+}
+
+// pluginSocketRemoved 判断事件是否为插件自身socket或整个socket目录被删除
+func (p *PPUDevicePlugin) pluginSocketRemoved(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	return name == filepath.Clean(p.socket) || name == filepath.Clean(p.socketPath)
+}
+
+// handleKubeletRestart 在kubelet重启或插件socket被删除后恢复服务：必要时重新启动gRPC服务器，然后重新注册；
+// 试运行模式下只重建socket
+func (p *PPUDevicePlugin) handleKubeletRestart() (err error) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	// 插件停止时会删除自身socket，此时不应重建
+	select {
+	case <-p.stop:
+		return nil
+	default:
+	}
+
+	// 恢复期间不再就绪，成功后重新标记为就绪
+	p.ready.Store(false)
+	defer func() { p.ready.Store(err == nil) }()
+
+	if _, err := os.Stat(p.socket); os.IsNotExist(err) {
+		p.log.Infof("Plugin socket %s is gone, restarting gRPC server", p.socket)
+		p.stopServer()
+		if err := p.serve(); err != nil {
+			return fmt.Errorf("failed to restart gRPC server: %w", err)
+		}
+		p.log.Infof("Recreated plugin socket %s", p.socket)
+	}
+
+	if p.opts.NoRegister {
+		return nil
+	}
+	return p.register(context.Background())
+}
@@ -0,0 +1,124 @@
+// Package testutil 提供测试设备插件所需的辅助工具
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// KubeletSocket 模拟kubelet在目录中监听的socket名称
+const KubeletSocket = "kubelet.sock"
+
+// FakeKubelet 进程内模拟的kubelet注册服务，记录收到的注册请求
+type FakeKubelet struct {
+	v1beta1.UnimplementedRegistrationServer
+
+	socket   string
+	server   *grpc.Server
+	requests chan *v1beta1.RegisterRequest
+
+	// mu保护以下字段，failures为开始接受注册前需要拒绝的次数，err非空时拒绝所有注册
+	mu       sync.Mutex
+	failures int
+	err      error
+	attempts int
+	received []*v1beta1.RegisterRequest
+}
+
+// NewFakeKubelet 在dir下的kubelet.sock上启动模拟的kubelet注册服务
+func NewFakeKubelet(dir string) (*FakeKubelet, error) {
+	socket := filepath.Join(dir, KubeletSocket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", socket, err)
+	}
+
+	k := &FakeKubelet{
+		socket:   socket,
+		server:   grpc.NewServer(),
+		requests: make(chan *v1beta1.RegisterRequest, 16),
+	}
+	v1beta1.RegisterRegistrationServer(k.server, k)
+	go k.server.Serve(listener)
+	return k, nil
+}
+
+// Register 记录注册请求，按配置返回错误或接受注册
+func (k *FakeKubelet) Register(ctx context.Context, request *v1beta1.RegisterRequest) (*v1beta1.Empty, error) {
+	k.mu.Lock()
+	k.attempts++
+	err := k.err
+	if err == nil && k.failures > 0 {
+		k.failures--
+		err = status.Error(codes.Unavailable, "kubelet not ready")
+	}
+	if err == nil {
+		k.received = append(k.received, request)
+	}
+	k.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case k.requests <- request:
+	default:
+	}
+	return &v1beta1.Empty{}, nil
+}
+
+// Requests 返回成功的注册请求通道
+func (k *FakeKubelet) Requests() <-chan *v1beta1.RegisterRequest {
+	return k.requests
+}
+
+// Received 返回目前为止成功的注册请求
+func (k *FakeKubelet) Received() []*v1beta1.RegisterRequest {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return append([]*v1beta1.RegisterRequest(nil), k.received...)
+}
+
+// Attempts 返回收到的注册请求总数，包括被拒绝的请求
+func (k *FakeKubelet) Attempts() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.attempts
+}
+
+// FailNext 使接下来的n次注册返回Unavailable
+func (k *FakeKubelet) FailNext(n int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.failures = n
+}
+
+// SetError 使之后的所有注册返回err，err为nil时恢复接受注册
+func (k *FakeKubelet) SetError(err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.err = err
+}
+
+// Socket 返回模拟kubelet的socket路径
+func (k *FakeKubelet) Socket() string {
+	return k.socket
+}
+
+// Stop 立即停止模拟的kubelet
+func (k *FakeKubelet) Stop() {
+	k.server.Stop()
+}